@@ -0,0 +1,67 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+// Command logstats reports statistics on log files and their rotated files:
+// most frequent messages, collapsed entries, errors and warnings per hour and
+// gaps between entries.
+package main
+
+import (
+	"flag"
+	"fmt"
+	masalog "github.com/masagroup/sw.golibs/log"
+	"os"
+	"regexp"
+)
+
+func main() {
+	top := flag.Int("top", 10, "number of most frequent messages to report")
+	gap := flag.Duration("gap", 0, "minimum delay between entries reported as a gap, defaults to 1m")
+	normalize := flag.Bool("normalize", true, "count messages differing only by numbers or identifiers together")
+	naming := flag.String("naming", "", "rotated log files naming, valid values are 'time', 'numeric' or a time layout like '2006-01-02', defaults to 'time', previous values can follow, like 'numeric,time'")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] log-file...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	scheme, err := masalog.ParseNamingScheme(*naming)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	options := masalog.StatsOptions{
+		Naming: scheme,
+		Top:    *top,
+		Gap:    *gap,
+	}
+	if *normalize {
+		options.Normalizers = []*regexp.Regexp{masalog.HexIDs, masalog.Numbers}
+	}
+	failed := false
+	for i, filename := range flag.Args() {
+		if i > 0 {
+			fmt.Println()
+		}
+		stats, err := masalog.AnalyzeLog(filename, options)
+		if err == nil {
+			err = stats.WriteReport(os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to analyze %s: %v\n", filename, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
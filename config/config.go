@@ -52,9 +52,20 @@ func saveJson(path string, data interface{}) error {
 }
 
 type Config struct {
-	flags    map[string]string
-	excludes map[string]struct{}
-	path     string
+	flags      map[string]string
+	excludes   map[string]struct{}
+	path       string
+	validators map[string]Validator
+	flagSet    *flag.FlagSet
+}
+
+// set returns the synchronized flag set, the command line flags by
+// default.
+func (c *Config) set() *flag.FlagSet {
+	if c.flagSet == nil {
+		return flag.CommandLine
+	}
+	return c.flagSet
 }
 
 func (c *Config) parseFlags(path string) error {
@@ -66,13 +77,13 @@ func (c *Config) parseFlags(path string) error {
 			return err
 		}
 	}
-	flag.Visit(func(flag *flag.Flag) {
+	c.set().Visit(func(flag *flag.Flag) {
 		_, ok := c.flags[flag.Name]
 		if ok {
 			delete(c.flags, flag.Name)
 		}
 	})
-	flag.VisitAll(func(flag *flag.Flag) {
+	c.set().VisitAll(func(flag *flag.Flag) {
 		val, ok := c.flags[flag.Name]
 		if ok {
 			_ = flag.Value.Set(val)
@@ -82,7 +93,7 @@ func (c *Config) parseFlags(path string) error {
 }
 
 func (c *Config) saveFlags() error {
-	flag.VisitAll(func(flag *flag.Flag) {
+	c.set().VisitAll(func(flag *flag.Flag) {
 		name := flag.Name
 		if _, ok := c.excludes[name]; !ok {
 			c.flags[name] = flag.Value.String()
@@ -94,13 +105,23 @@ func (c *Config) saveFlags() error {
 	return nil
 }
 
+// NewConfig creates a configuration synchronized with the command line
+// flags, see NewConfigWithFlagSet.
 func NewConfig(path string, excludes []string) (*Config, error) {
+	return NewConfigWithFlagSet(flag.CommandLine, path, excludes)
+}
+
+// NewConfigWithFlagSet creates a configuration synchronized with the flags
+// of set, except for excludes, and parses the given configuration file. A
+// nil set stands for the command line flags.
+func NewConfigWithFlagSet(set *flag.FlagSet, path string, excludes []string) (*Config, error) {
 	ignores := map[string]struct{}{}
 	for _, v := range excludes {
 		ignores[v] = struct{}{}
 	}
 	c := &Config{
 		excludes: ignores,
+		flagSet:  set,
 	}
 	return c, c.Parse(path)
 }
@@ -116,7 +137,13 @@ func (c *Config) Parse(path string) error {
 	return c.saveFlags()
 }
 
+// Update sets the value of key and saves the configuration file. Values
+// rejected by the key validator are not set.
 func (c *Config) Update(key, value string) error {
+	err := c.validate(key, value)
+	if err != nil {
+		return err
+	}
 	c.flags[key] = value
 	if c.path != "" {
 		return saveJson(c.path, &c.flags)
@@ -124,6 +151,11 @@ func (c *Config) Update(key, value string) error {
 	return nil
 }
 
+// Path returns the configuration file path, empty if there is none.
+func (c *Config) Path() string {
+	return c.path
+}
+
 func (c *Config) GetFlag(key string) string {
 	value, ok := c.flags[key]
 	if ok {
@@ -25,18 +25,21 @@ func makeDir(t *testing.T) string {
 }
 
 func TestParseConfig(t *testing.T) {
-	flag.String("config-test", "", "config test flag")
-	flag.String("ignore", "", "flag ignored")
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String("config-test", "", "config test flag")
+	set.String("ignore", "", "flag ignored")
 
 	dir := makeDir(t)
+	defer os.RemoveAll(dir)
 	configFile := filepath.Join(dir, "config.cfg")
-	config, err := NewConfig(configFile, []string{"ignore"})
+	config, err := NewConfigWithFlagSet(set, configFile, []string{"ignore"})
 	assert.NoError(t, err)
+	assert.Equal(t, configFile, config.Path())
 
 	_, err = os.Stat(configFile)
 	assert.NoError(t, err)
 
-	testFlag := flag.Lookup("config-test")
+	testFlag := set.Lookup("config-test")
 	assert.Equal(t, testFlag.Value.String(), "")
 
 	err = config.Update("config-test", "test")
@@ -46,6 +49,34 @@ func TestParseConfig(t *testing.T) {
 
 	assert.Equal(t, config.GetFlag("config-test"), "test")
 
-	testFlag = flag.Lookup("config-test")
+	testFlag = set.Lookup("config-test")
 	assert.Equal(t, testFlag.Value.String(), "test")
 }
+
+func TestFlagSetValuesOverrideConfig(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.cfg")
+	assert.NoError(t, ioutil.WriteFile(configFile, []byte(`{"port": "80", "mode": "safe"}`), os.ModePerm))
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	port := set.Int("port", 0, "port")
+	mode := set.String("mode", "", "mode")
+	assert.NoError(t, set.Parse([]string{"-port", "8080"}))
+	config, err := NewConfigWithFlagSet(set, configFile, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 8080, *port)
+	assert.Equal(t, "safe", *mode)
+	assert.Equal(t, "8080", config.GetFlag("port"))
+	assert.Nil(t, flag.Lookup("port"))
+}
+
+func TestConfigDefaultsToCommandLineFlags(t *testing.T) {
+	var config Config
+	assert.NoError(t, config.Parse(""))
+	assert.Equal(t, flag.Lookup("test.v").Value.String(), config.GetFlag("test.v"))
+	c, err := NewConfigWithFlagSet(nil, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, flag.Lookup("test.v").Value.String(), c.GetFlag("test.v"))
+}
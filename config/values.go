@@ -0,0 +1,173 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator checks a configuration value and returns an error describing
+// why it is invalid.
+type Validator func(value string) error
+
+// IntRange accepts integers between min and max included.
+func IntRange(min, max int) Validator {
+	return func(value string) error {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if i < min || i > max {
+			return fmt.Errorf("out of range [%d, %d]", min, max)
+		}
+		return nil
+	}
+}
+
+// OneOf accepts the supplied values only.
+func OneOf(values ...string) Validator {
+	return func(value string) error {
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(values, ", "))
+	}
+}
+
+// SetValidator registers a validator for key, checked by Update, Validate
+// and the typed getters. Empty values are not validated.
+func (c *Config) SetValidator(key string, v Validator) {
+	if c.validators == nil {
+		c.validators = map[string]Validator{}
+	}
+	c.validators[key] = v
+}
+
+func (c *Config) invalid(key, value string, err error) error {
+	if c.path == "" {
+		return fmt.Errorf("invalid value %q for %s: %v", value, key, err)
+	}
+	return fmt.Errorf("invalid value %q for %s in %s: %v", value, key, c.path, err)
+}
+
+func (c *Config) validate(key, value string) error {
+	v, ok := c.validators[key]
+	if !ok || value == "" {
+		return nil
+	}
+	err := v(value)
+	if err != nil {
+		return c.invalid(key, value, err)
+	}
+	return nil
+}
+
+// Validate checks all values having a validator and returns the first
+// error, in key order.
+func (c *Config) Validate() error {
+	keys := make([]string, 0, len(c.validators))
+	for key := range c.validators {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		err := c.validate(key, c.flags[key])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the validated value of key, or false if it is missing or
+// empty.
+func (c *Config) lookup(key string) (string, bool, error) {
+	value := c.flags[key]
+	if value == "" {
+		return "", false, nil
+	}
+	return value, true, c.validate(key, value)
+}
+
+// GetInt returns the value of key as an integer, or def if it is missing
+// or empty.
+func (c *Config) GetInt(key string, def int) (int, error) {
+	value, ok, err := c.lookup(key)
+	if !ok || err != nil {
+		return def, err
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return def, c.invalid(key, value, err)
+	}
+	return i, nil
+}
+
+// GetBool returns the value of key as a boolean, accepting the values of
+// strconv.ParseBool, or def if it is missing or empty.
+func (c *Config) GetBool(key string, def bool) (bool, error) {
+	value, ok, err := c.lookup(key)
+	if !ok || err != nil {
+		return def, err
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def, c.invalid(key, value, err)
+	}
+	return b, nil
+}
+
+// GetFloat returns the value of key as a float, or def if it is missing or
+// empty.
+func (c *Config) GetFloat(key string, def float64) (float64, error) {
+	value, ok, err := c.lookup(key)
+	if !ok || err != nil {
+		return def, err
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return def, c.invalid(key, value, err)
+	}
+	return f, nil
+}
+
+// GetDuration returns the value of key as a duration like "1m30s", or def
+// if it is missing or empty.
+func (c *Config) GetDuration(key string, def time.Duration) (time.Duration, error) {
+	value, ok, err := c.lookup(key)
+	if !ok || err != nil {
+		return def, err
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return def, c.invalid(key, value, err)
+	}
+	return d, nil
+}
+
+// GetStrings returns the comma separated values of key without surrounding
+// spaces, or def if it is missing or empty.
+func (c *Config) GetStrings(key string, def []string) ([]string, error) {
+	value, ok, err := c.lookup(key)
+	if !ok || err != nil {
+		return def, err
+	}
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return values, nil
+}
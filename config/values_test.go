@@ -0,0 +1,86 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func makeConfig(flags map[string]string) *Config {
+	return &Config{flags: flags, path: "app.cfg"}
+}
+
+func TestTypedGettersParseValues(t *testing.T) {
+	c := makeConfig(map[string]string{
+		"port":    "8080",
+		"daemon":  "true",
+		"ratio":   "0.5",
+		"timeout": "1m30s",
+		"hosts":   "a, b,c",
+		"empty":   "",
+	})
+	i, err := c.GetInt("port", 80)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, i)
+	b, err := c.GetBool("daemon", false)
+	assert.NoError(t, err)
+	assert.True(t, b)
+	f, err := c.GetFloat("ratio", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+	d, err := c.GetDuration("timeout", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+	s, err := c.GetStrings("hosts", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, s)
+	// Missing and empty values get the default.
+	i, err = c.GetInt("missing", 80)
+	assert.NoError(t, err)
+	assert.Equal(t, 80, i)
+	d, err = c.GetDuration("empty", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, d)
+}
+
+func TestTypedGettersReportInvalidValues(t *testing.T) {
+	c := makeConfig(map[string]string{"port": "eighty"})
+	i, err := c.GetInt("port", 80)
+	assert.Equal(t, 80, i)
+	assert.EqualError(t, err, `invalid value "eighty" for port in app.cfg: `+
+		`strconv.Atoi: parsing "eighty": invalid syntax`)
+	_, err = c.GetBool("port", false)
+	assert.Error(t, err)
+	_, err = c.GetFloat("port", 0)
+	assert.Error(t, err)
+	_, err = c.GetDuration("port", 0)
+	assert.Error(t, err)
+	c.path = ""
+	_, err = c.GetInt("port", 80)
+	assert.EqualError(t, err, `invalid value "eighty" for port: `+
+		`strconv.Atoi: parsing "eighty": invalid syntax`)
+}
+
+func TestValidatorsCheckValues(t *testing.T) {
+	c := makeConfig(map[string]string{"port": "70000", "mode": "fast"})
+	c.path = ""
+	c.SetValidator("port", IntRange(1, 65535))
+	c.SetValidator("mode", OneOf("fast", "safe"))
+	_, err := c.GetInt("port", 80)
+	assert.EqualError(t, err, `invalid value "70000" for port: out of range [1, 65535]`)
+	assert.Equal(t, err, c.Validate())
+	assert.NoError(t, c.Update("port", "8080"))
+	assert.NoError(t, c.Validate())
+	err = c.Update("mode", "slow")
+	assert.EqualError(t, err, `invalid value "slow" for mode: expected one of fast, safe`)
+	assert.Equal(t, "fast", c.GetFlag("mode"))
+}
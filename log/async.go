@@ -0,0 +1,147 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned when using a closed AsyncWriter.
+var ErrClosed = errors.New("writer is closed")
+
+type asyncEntry struct {
+	data    []byte
+	flushed chan struct{}
+}
+
+// AsyncWriter queues writes and forwards them to the underlying writer from a
+// background goroutine, so that callers never wait on slow disks unless the
+// queue is full and blocking mode is selected.
+type AsyncWriter struct {
+	w     io.Writer
+	drop  bool
+	queue chan asyncEntry
+	done  chan struct{}
+	// mutex prevents writes on a closed queue.
+	mutex   sync.RWMutex
+	closed  bool
+	dropped int64
+	errLock sync.Mutex
+	err     error
+}
+
+// NewAsyncWriter creates an AsyncWriter forwarding to w with a queue of size
+// entries. When the queue is full, writes either block or are dropped
+// depending on drop.
+func NewAsyncWriter(w io.Writer, size int, drop bool) *AsyncWriter {
+	a := &AsyncWriter{
+		w:     w,
+		drop:  drop,
+		queue: make(chan asyncEntry, size),
+		done:  make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	for entry := range a.queue {
+		if entry.flushed != nil {
+			close(entry.flushed)
+			continue
+		}
+		_, err := a.w.Write(entry.data)
+		if err != nil {
+			a.errLock.Lock()
+			a.err = err
+			a.errLock.Unlock()
+		}
+	}
+}
+
+// Write queues a copy of p. Errors from the underlying writer are reported
+// by Flush and Close.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.closed {
+		return 0, ErrClosed
+	}
+	entry := asyncEntry{data: append([]byte(nil), p...)}
+	if !a.drop {
+		a.queue <- entry
+		return len(p), nil
+	}
+	select {
+	case a.queue <- entry:
+	default:
+		atomic.AddInt64(&a.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of writes dropped because the queue was full.
+func (a *AsyncWriter) Dropped() int64 {
+	return atomic.LoadInt64(&a.dropped)
+}
+
+// Pending returns the number of writes waiting in the queue.
+func (a *AsyncWriter) Pending() int {
+	return len(a.queue)
+}
+
+func (a *AsyncWriter) takeError() error {
+	a.errLock.Lock()
+	defer a.errLock.Unlock()
+	err := a.err
+	a.err = nil
+	return err
+}
+
+// Flush waits for all queued writes to reach the underlying writer and
+// returns the last error it reported, if any.
+func (a *AsyncWriter) Flush() error {
+	a.mutex.RLock()
+	if a.closed {
+		a.mutex.RUnlock()
+		return ErrClosed
+	}
+	flushed := make(chan struct{})
+	a.queue <- asyncEntry{flushed: flushed}
+	a.mutex.RUnlock()
+	<-flushed
+	return a.takeError()
+}
+
+// Close drains the queue and closes the underlying writer if it implements
+// io.Closer.
+func (a *AsyncWriter) Close() error {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return ErrClosed
+	}
+	a.closed = true
+	close(a.queue)
+	a.mutex.Unlock()
+	<-a.done
+	err := a.takeError()
+	if c, ok := a.w.(io.Closer); ok {
+		cerr := c.Close()
+		if err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type blockingWriter struct {
+	bytes.Buffer
+	release chan struct{}
+	closed  bool
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.Buffer.Write(p)
+}
+
+func (w *blockingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestAsyncWriterFlushesQueuedWrites(t *testing.T) {
+	b := &blockingWriter{release: make(chan struct{})}
+	close(b.release)
+	w := NewAsyncWriter(b, 10, false)
+	for i := 0; i < 100; i++ {
+		checkWriteLine(t, w)
+	}
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, strings.Repeat(someline, 100), b.String())
+	checkWrite(t, w)
+	assert.NoError(t, w.Close())
+	assert.True(t, b.closed)
+	assert.Equal(t, strings.Repeat(someline, 100)+content, b.String())
+	_, err := w.Write([]byte(content))
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, ErrClosed, w.Close())
+}
+
+func TestAsyncWriterDropsWritesWhenFull(t *testing.T) {
+	b := &blockingWriter{release: make(chan struct{})}
+	w := NewAsyncWriter(b, 2, true)
+	for i := 0; i < 10; i++ {
+		checkWriteLine(t, w)
+	}
+	assert.True(t, w.Dropped() >= 7)
+	close(b.release)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, int64(10), w.Dropped()+int64(strings.Count(b.String(), "\n")))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestAsyncWriterReportsErrorsOnFlush(t *testing.T) {
+	w := NewAsyncWriter(failingWriter{}, 2, false)
+	checkWrite(t, w)
+	assert.EqualError(t, w.Flush(), "disk full")
+	assert.NoError(t, w.Flush())
+	assert.NoError(t, w.Close())
+}
+
+func TestRotatingLogSupportsConcurrentWrites(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 10, "lines", true)
+	assert.NoError(t, err)
+	c := MakeCollapsingWriter(w)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, err := c.Write([]byte(strings.Repeat("x", i+1) + "\n"))
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	// Flush pending repeats.
+	_, err = c.Write([]byte("end\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	data := []byte{}
+	for _, name := range readFiles(t, dir) {
+		if strings.HasSuffix(name, ".state") {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		data = append(data, b...)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	total := 0
+	for _, line := range lines {
+		if i := strings.Index(line, "...x"); i >= 0 {
+			n := 0
+			for _, c := range line[i+4:] {
+				n = n*10 + int(c-'0')
+			}
+			total += n - 1
+		} else {
+			total++
+		}
+	}
+	assert.Equal(t, 401, total)
+}
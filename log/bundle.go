@@ -0,0 +1,355 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BundleOptions configures CollectBundle.
+type BundleOptions struct {
+	// Format is either "zip", the default, or "tar.gz".
+	Format string
+	// Logs lists log files included along with their rotated files.
+	Logs []string
+	// Naming names rotated log files, it defaults to TimeNaming.
+	Naming NamingScheme
+	// Files lists other files to include, like configuration files.
+	Files []string
+	// From and To restrict included log entries to [From, To), zero values
+	// leave the range open.
+	From time.Time
+	To   time.Time
+	// Redactor masks sensitive values of included files, nil includes them
+	// unchanged.
+	Redactor *Redactor
+	// SystemInfo adds basic information about the system and the process.
+	SystemInfo bool
+}
+
+// BundleEntry describes a bundle file in its manifest. Files which could not
+// be read are listed with an error.
+type BundleEntry struct {
+	Name     string    `json:"name,omitempty"`
+	Source   string    `json:"source,omitempty"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// BundleManifest is stored as "manifest.json" at the root of bundles.
+type BundleManifest struct {
+	Created  time.Time     `json:"created"`
+	From     *time.Time    `json:"from,omitempty"`
+	To       *time.Time    `json:"to,omitempty"`
+	Redacted bool          `json:"redacted"`
+	Files    []BundleEntry `json:"files"`
+}
+
+type bundleArchive interface {
+	// add stores a file whose content is written by write.
+	add(name string, modified time.Time, write func(io.Writer) error) error
+	Close() error
+}
+
+type zipArchive struct {
+	w *zip.Writer
+}
+
+func (a zipArchive) add(name string, modified time.Time, write func(io.Writer) error) error {
+	w, err := a.w.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	return write(w)
+}
+
+func (a zipArchive) Close() error {
+	return a.w.Close()
+}
+
+type tarArchive struct {
+	gz *gzip.Writer
+	w  *tar.Writer
+}
+
+// add writes the content to a temporary file first since tar headers hold
+// file sizes.
+func (a tarArchive) add(name string, modified time.Time, write func(io.Writer) error) error {
+	tmp, err := ioutil.TempFile("", "bundle")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	err = write(tmp)
+	if err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	err = a.w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(a.w, tmp)
+	return err
+}
+
+func (a tarArchive) Close() error {
+	err := a.w.Close()
+	gerr := a.gz.Close()
+	if err != nil {
+		return err
+	}
+	return gerr
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type bundler struct {
+	archive  bundleArchive
+	options  BundleOptions
+	manifest BundleManifest
+	names    map[string]bool
+}
+
+// name returns a unique archive name for base in dir.
+func (b *bundler) name(dir, base string) string {
+	ext := filepath.Ext(base)
+	name := path.Join(dir, base)
+	for i := 2; b.names[name]; i++ {
+		name = path.Join(dir, strings.TrimSuffix(base, ext)+"~"+strconv.Itoa(i)+ext)
+	}
+	b.names[name] = true
+	return name
+}
+
+// copy writes r to w, keeping the lines in the time window when filter is
+// set and masking sensitive values.
+func (b *bundler) copy(w io.Writer, r io.Reader, filter bool) error {
+	if !filter && b.options.Redactor == nil {
+		_, err := io.Copy(w, r)
+		return err
+	}
+	window := newTimeWindow(time.Time{}, time.Time{})
+	if filter {
+		window = newTimeWindow(b.options.From, b.options.To)
+	}
+	reader := bufio.NewReader(r)
+	for !window.finished {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 && window.accept(line) {
+			data := []byte(line)
+			if b.options.Redactor != nil {
+				data = b.options.Redactor.Redact(data)
+			}
+			_, werr := w.Write(data)
+			if werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceReader records read errors of bundled files and ends their content
+// instead of failing the collection.
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF {
+		s.err = err
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// addFile stores source as dir/base, filtering its lines by time when
+// filter is set. Files which cannot be read are reported in the manifest.
+func (b *bundler) addFile(dir, source string, filter bool) error {
+	entry := BundleEntry{Source: source}
+	r, err := openLogFile(source)
+	if err != nil {
+		entry.Error = err.Error()
+		b.manifest.Files = append(b.manifest.Files, entry)
+		return nil
+	}
+	defer r.Close()
+	info, err := os.Stat(source)
+	if err == nil {
+		entry.Modified = info.ModTime()
+	}
+	entry.Name = b.name(dir, filepath.Base(uncompressedName(source)))
+	src := &sourceReader{r: r}
+	err = b.archive.add(entry.Name, entry.Modified, func(w io.Writer) error {
+		counter := &countingWriter{w: w}
+		err := b.copy(counter, src, filter)
+		entry.Size = counter.n
+		return err
+	})
+	if src.err != nil {
+		entry.Error = src.err.Error()
+	}
+	b.manifest.Files = append(b.manifest.Files, entry)
+	return err
+}
+
+func (b *bundler) addLog(filename string) error {
+	filter := !b.options.From.IsZero() || !b.options.To.IsZero()
+	history, err := readableHistory(filename, b.options.Naming, b.options.From, map[string]bool{})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, rotated := range history {
+		err = b.addFile("logs", rotated, filter)
+		if err != nil {
+			return err
+		}
+	}
+	return b.addFile("logs", filename, filter)
+}
+
+func (b *bundler) addSystemInfo() error {
+	return b.archive.add("sysinfo.txt", b.manifest.Created, func(w io.Writer) error {
+		hostname, _ := os.Hostname()
+		executable, _ := os.Executable()
+		wd, _ := os.Getwd()
+		args := os.Args
+		if b.options.Redactor != nil {
+			args = b.options.Redactor.RedactArgs(args)
+		}
+		_, err := fmt.Fprintf(w, "time: %s\nhostname: %s\nos: %s/%s\ncpus: %d\ngo: %s\n"+
+			"pid: %d\nexecutable: %s\nworking-directory: %s\ncommand line: %v\n",
+			b.manifest.Created.Format(time.RFC3339), hostname, runtime.GOOS,
+			runtime.GOARCH, runtime.NumCPU(), runtime.Version(), os.Getpid(),
+			executable, wd, args)
+		return err
+	})
+}
+
+// CollectBundle writes to w an archive gathering log files with their
+// rotated history, other files like configuration files and optionally
+// system information, for bug reports. Compressed rotated files are stored
+// uncompressed. A "manifest.json" file describes the archive content,
+// including the files which could not be read.
+func CollectBundle(w io.Writer, options BundleOptions) error {
+	if options.Naming == nil {
+		options.Naming = TimeNaming{}
+	}
+	b := &bundler{
+		options: options,
+		names:   map[string]bool{},
+	}
+	switch options.Format {
+	case "", "zip":
+		b.archive = zipArchive{zip.NewWriter(w)}
+	case "tar.gz":
+		gz := gzip.NewWriter(w)
+		b.archive = tarArchive{gz: gz, w: tar.NewWriter(gz)}
+	default:
+		return fmt.Errorf("invalid bundle format: %q", options.Format)
+	}
+	b.manifest = BundleManifest{
+		Created:  now(),
+		Redacted: options.Redactor != nil,
+		Files:    []BundleEntry{},
+	}
+	if !options.From.IsZero() {
+		b.manifest.From = &options.From
+	}
+	if !options.To.IsZero() {
+		b.manifest.To = &options.To
+	}
+	err := b.collect()
+	cerr := b.archive.Close()
+	if err != nil {
+		return err
+	}
+	return cerr
+}
+
+func (b *bundler) collect() error {
+	for _, filename := range b.options.Logs {
+		err := b.addLog(filename)
+		if err != nil {
+			return err
+		}
+	}
+	for _, filename := range b.options.Files {
+		err := b.addFile("files", filename, false)
+		if err != nil {
+			return err
+		}
+	}
+	if b.options.SystemInfo {
+		err := b.addSystemInfo()
+		if err != nil {
+			return err
+		}
+	}
+	return b.archive.add("manifest.json", b.manifest.Created, func(w io.Writer) error {
+		data, err := json.MarshalIndent(&b.manifest, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,159 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readZip returns the content of a zip archive by file name, in order.
+func readZip(t *testing.T, data []byte) ([]string, map[string]string) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	names := []string{}
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		names = append(names, f.Name)
+		files[f.Name] = string(content)
+	}
+	return names, files
+}
+
+func readTarGz(t *testing.T, data []byte) ([]string, map[string]string) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	r := tar.NewReader(gz)
+	names := []string{}
+	files := map[string]string{}
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		names = append(names, h.Name)
+		files[h.Name] = string(content)
+	}
+	return names, files
+}
+
+func readManifest(t *testing.T, data string) BundleManifest {
+	manifest := BundleManifest{}
+	assert.NoError(t, json.Unmarshal([]byte(data), &manifest))
+	return manifest
+}
+
+func TestCollectBundleGathersLogsAndFiles(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	writeGzip(t, filepath.Join(dir, rotated1+".gz"), "first\n")
+	assert.NoError(t, os.Remove(filepath.Join(dir, rotated1)))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, rotated2), []byte("second\n"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filename), []byte("third\n"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"password": "secret"}`), os.ModePerm))
+	buf := &bytes.Buffer{}
+	err := CollectBundle(buf, BundleOptions{
+		Logs:       []string{filepath.Join(dir, filename)},
+		Files:      []string{filepath.Join(dir, "config.json"), filepath.Join(dir, "missing.json")},
+		Redactor:   Sensitive(),
+		SystemInfo: true,
+	})
+	assert.NoError(t, err)
+	names, files := readZip(t, buf.Bytes())
+	assert.Equal(t, []string{
+		"logs/" + rotated1,
+		"logs/" + rotated2,
+		"logs/" + filename,
+		"files/config.json",
+		"sysinfo.txt",
+		"manifest.json",
+	}, names)
+	assert.Equal(t, "first\n", files["logs/"+rotated1])
+	assert.Equal(t, "second\n", files["logs/"+rotated2])
+	assert.Equal(t, "third\n", files["logs/"+filename])
+	assert.Equal(t, `{"password": ***}`, files["files/config.json"])
+	assert.Contains(t, files["sysinfo.txt"], "hostname: ")
+	manifest := readManifest(t, files["manifest.json"])
+	assert.True(t, manifest.Redacted)
+	assert.Nil(t, manifest.From)
+	assert.Len(t, manifest.Files, 5)
+	assert.Equal(t, filepath.Join(dir, rotated1+".gz"), manifest.Files[0].Source)
+	assert.EqualValues(t, len("first\n"), manifest.Files[0].Size)
+	missing := manifest.Files[4]
+	assert.Equal(t, "", missing.Name)
+	assert.Equal(t, filepath.Join(dir, "missing.json"), missing.Source)
+	assert.NotEmpty(t, missing.Error)
+}
+
+func TestCollectBundleRestrictsLogsToTimeWindow(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	old := filepath.Join(dir, rotated1)
+	assert.NoError(t, ioutil.WriteFile(old, []byte("[2016-03-13 10:00:00] old\n"), os.ModePerm))
+	before := time.Date(2016, 3, 13, 12, 0, 0, 0, time.Local)
+	assert.NoError(t, os.Chtimes(old, before, before))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filename), []byte(
+		"[2016-03-14 09:00:00] early\n"+
+			"[2016-03-14 10:00:00] panic\n"+
+			"stack\n"+
+			"[2016-03-14 11:00:00] late\n"), os.ModePerm))
+	buf := &bytes.Buffer{}
+	err := CollectBundle(buf, BundleOptions{
+		Format: "tar.gz",
+		Logs:   []string{filepath.Join(dir, filename), filepath.Join(dir, "other", filename)},
+		From:   time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local),
+		To:     time.Date(2016, 3, 14, 11, 0, 0, 0, time.Local),
+	})
+	assert.NoError(t, err)
+	names, files := readTarGz(t, buf.Bytes())
+	assert.Equal(t, []string{"logs/" + filename, "manifest.json"}, names)
+	assert.Equal(t, "[2016-03-14 10:00:00] panic\nstack\n", files["logs/"+filename])
+	manifest := readManifest(t, files["manifest.json"])
+	assert.False(t, manifest.Redacted)
+	assert.NotNil(t, manifest.From)
+	assert.Len(t, manifest.Files, 2)
+	assert.NotEmpty(t, manifest.Files[1].Error)
+}
+
+func TestCollectBundleNamesFilesUniquely(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "debug"), os.ModePerm))
+	for _, name := range []string{filename, filepath.Join("debug", filename)} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(name), os.ModePerm))
+	}
+	buf := &bytes.Buffer{}
+	err := CollectBundle(buf, BundleOptions{
+		Logs: []string{filepath.Join(dir, filename), filepath.Join(dir, "debug", filename)},
+	})
+	assert.NoError(t, err)
+	names, files := readZip(t, buf.Bytes())
+	assert.Equal(t, []string{"logs/filename.log", "logs/filename~2.log", "manifest.json"}, names)
+	assert.Equal(t, filepath.Join("debug", filename), files["logs/filename~2.log"])
+	assert.Error(t, CollectBundle(buf, BundleOptions{Format: "rar"}))
+}
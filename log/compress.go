@@ -0,0 +1,163 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Compressor compresses rotated log files.
+type Compressor interface {
+	// Ext returns the extension appended to compressed files, for instance
+	// ".gz".
+	Ext() string
+	// NewWriter returns a writer compressing its input into w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// GzipCompressor compresses rotated log files with gzip.
+type GzipCompressor struct {
+	// Level is a compress/gzip compression level, zero means
+	// gzip.DefaultCompression.
+	Level int
+}
+
+func (c GzipCompressor) Ext() string {
+	return ".gz"
+}
+
+func (c GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// compressedExts lists the extensions of compressed rotated files which are
+// recognized regardless of the configured compressor, so that changing it
+// does not hide existing history.
+var compressedExts = []string{".gz", ".zst"}
+
+func isCompressed(filename string, c Compressor) bool {
+	if c != nil && strings.HasSuffix(filename, c.Ext()) {
+		return true
+	}
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(filename, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressFile compresses filename into filename + c.Ext() and returns the
+// compressed file path. The source file is left untouched. Data is written to
+// a unique temporary file first so that neither an interrupted compression
+// nor another process compressing the same file leaves a truncated archive in
+// the history.
+func compressFile(c Compressor, filename string) (string, error) {
+	src, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return "", err
+	}
+	compressed := filename + c.Ext()
+	dst, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(compressed)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmp := dst.Name()
+	err = func() error {
+		defer dst.Close()
+		err := dst.Chmod(info.Mode())
+		if err != nil {
+			return err
+		}
+		zw, err := c.NewWriter(dst)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(zw, src)
+		if err != nil {
+			zw.Close()
+			return err
+		}
+		err = zw.Close()
+		if err != nil {
+			return err
+		}
+		return dst.Close()
+	}()
+	if err == nil {
+		err = os.Rename(tmp, compressed)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return compressed, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
+
+// errUnsupportedCompression reports compressed files which cannot be read,
+// like zstd ones.
+var errUnsupportedCompression = errors.New("unsupported compression")
+
+// openLogFile opens a current or rotated log file, decompressing gzip
+// compressed files on the fly.
+func openLogFile(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !isCompressed(filename, nil) {
+		return file, nil
+	}
+	if !strings.HasSuffix(filename, GzipCompressor{}.Ext()) {
+		file.Close()
+		return nil, &os.PathError{Op: "open", Path: filename, Err: errUnsupportedCompression}
+	}
+	r, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+	}
+	return gzipReadCloser{Reader: r, file: file}, nil
+}
+
+// uncompressedName returns filename without its compression extension.
+func uncompressedName(filename string) string {
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(filename, ext) {
+			return strings.TrimSuffix(filename, ext)
+		}
+	}
+	return filename
+}
@@ -0,0 +1,198 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	compressed = `^filename\.\d{8}T\d{6}\.log(\.\d+)*\.gz$`
+)
+
+func checkGzipContent(t *testing.T, filename, text string) {
+	file, err := os.Open(filename)
+	assert.NoError(t, err)
+	defer file.Close()
+	r, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), text)
+}
+
+func TestRotatingLogCompressesRotatedFiles(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 3, int64(len(content)), "bytes", true,
+		WithCompression(GzipCompressor{}))
+	assert.NoError(t, err)
+	checkWrite(t, w)
+	checkWrite(t, w)
+	assert.NoError(t, w.Close())
+	files := readFiles(t, dir)
+	assert.Len(t, files, 2)
+	assert.Regexp(t, compressed, files[0])
+	assert.Equal(t, files[1], filename)
+	checkGzipContent(t, filepath.Join(dir, files[0]), content)
+	checkContent(t, filepath.Join(dir, files[1]), content)
+}
+
+func TestRotatingLogCompressesExistingHistory(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 3, 3, "bytes", false,
+		WithCompression(GzipCompressor{}))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	files := readFiles(t, dir)
+	assert.Equal(t, []string{rotated1 + ".gz", rotated2 + ".gz",
+		unrelated1, filename, unrelated2, unrelated3}, files)
+	checkGzipContent(t, filepath.Join(dir, rotated1+".gz"), "")
+}
+
+func TestRotatingLogPrunesCompressedFiles(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{rotated1, rotated2} {
+		err := os.Rename(filepath.Join(dir, name), filepath.Join(dir, name+".gz"))
+		assert.NoError(t, err)
+	}
+	err := ioutil.WriteFile(filepath.Join(dir, filename), []byte(content), os.ModePerm)
+	assert.NoError(t, err)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 2, 3, "bytes", true)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	files := readFiles(t, dir)
+	assert.Len(t, files, 5)
+	assert.NotContains(t, files, rotated1+".gz")
+	assert.NotContains(t, files, rotated2+".gz")
+	assert.Contains(t, files, filename)
+}
+
+type failingCompressor struct{}
+
+func (c failingCompressor) Ext() string {
+	return ".gz"
+}
+
+func (c failingCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nil, errors.New("compressor failure")
+}
+
+func TestRotatingLogReportsCompressionFailures(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, int64(len(someline)), "bytes", true,
+		WithCompression(failingCompressor{}))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWriteLine(t, w)
+	checkWriteLine(t, w)
+	w.compressing.Wait()
+	checkWriteLine(t, w)
+	checkContent(t, filepath.Join(dir, filename),
+		"failed to compress log file: compressor failure\n"+someline)
+	assert.EqualValues(t, 1, w.Stats().Errors)
+	w.compressing.Wait()
+	files := readFiles(t, dir)
+	assert.Len(t, files, 3)
+	assert.Regexp(t, created, files[0])
+	assert.NotRegexp(t, compressed, files[0])
+}
+
+func TestConcurrentCompressionsProduceValidArchives(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, rotated1)
+	data := strings.Repeat(someline, 10000)
+	assert.NoError(t, ioutil.WriteFile(source, []byte(data), os.ModePerm))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := compressFile(GzipCompressor{}, source)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []string{rotated1, rotated1 + ".gz"}, readFiles(t, dir))
+	checkGzipContent(t, source+".gz", data)
+}
+
+// gatedCompressor is a gzip compressor waiting for gate before compressing
+// and recording the maximum number of concurrent compressions.
+type gatedCompressor struct {
+	gate    chan struct{}
+	active  *int32
+	maximum *int32
+}
+
+type gatedWriter struct {
+	io.WriteCloser
+	c gatedCompressor
+}
+
+func (w gatedWriter) Close() error {
+	atomic.AddInt32(w.c.active, -1)
+	return w.WriteCloser.Close()
+}
+
+func (c gatedCompressor) Ext() string {
+	return ".gz"
+}
+
+func (c gatedCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	active := atomic.AddInt32(c.active, 1)
+	if active > atomic.LoadInt32(c.maximum) {
+		atomic.StoreInt32(c.maximum, active)
+	}
+	<-c.gate
+	zw, err := GzipCompressor{}.NewWriter(w)
+	return gatedWriter{zw, c}, err
+}
+
+func TestRotatingLogCompressesOneFileAtATime(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	c := gatedCompressor{make(chan struct{}), new(int32), new(int32)}
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 0, "bytes", false,
+		WithCompression(c))
+	assert.NoError(t, err)
+	closed := make(chan error)
+	go func() {
+		closed <- w.Close()
+	}()
+	// Writes are not blocked by pending compressions.
+	assert.Eventually(t, func() bool {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		return w.file == nil
+	}, time.Second, time.Millisecond)
+	checkWriteLine(t, w)
+	close(c.gate)
+	assert.NoError(t, <-closed)
+	assert.NoError(t, w.Close())
+	assert.EqualValues(t, 1, atomic.LoadInt32(c.maximum))
+	assert.Contains(t, readFiles(t, dir), rotated1+".gz")
+	assert.Contains(t, readFiles(t, dir), rotated2+".gz")
+}
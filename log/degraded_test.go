@@ -0,0 +1,87 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingLogFallsBackWhenReopeningFails(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	fallback := &bytes.Buffer{}
+	path := filepath.Join(dir, filename)
+	w, err := NewRotateWriter(path, -1, 1, "bytes", true,
+		WithFallback(fallback),
+		WithRetryInterval(time.Minute),
+		OnRotate(func(oldPath, newPath string) {
+			// Prevent the new log file creation.
+			assert.NoError(t, os.Mkdir(oldPath, os.ModePerm))
+		}))
+	assert.NoError(t, err)
+	defer w.Close()
+	for _, data := range []string{"a", "b"} {
+		n, err := w.Write([]byte(data))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	assert.Error(t, w.Failure())
+	assert.NoError(t, os.Remove(path))
+	// Reopening waits for the retry interval.
+	advance(30 * time.Second)
+	checkWrite(t, w)
+	assert.Error(t, w.Failure())
+	assert.EqualValues(t, 1+len(content), w.Dropped())
+	advance(30 * time.Second)
+	_, err = w.Write([]byte("d"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Failure())
+	assert.True(t, strings.HasPrefix(fallback.String(), "failed to write log file "))
+	assert.True(t, strings.HasSuffix(fallback.String(), "\nb"+content))
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^recovered from log file failure, 13 bytes were not written: .+\nd$", string(data))
+	// Dropped bytes are counted once.
+	assert.EqualValues(t, 1+len(content), w.Dropped())
+}
+
+func TestRotatingLogFallsBackOnWriteFailures(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full device")
+	}
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	path := filepath.Join(dir, filename)
+	assert.NoError(t, os.Symlink("/dev/full", path))
+	w, err := NewRotateWriter(path, -1, 0, "bytes", false, WithFallback(nil))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWrite(t, w)
+	assert.Error(t, w.Failure())
+	assert.EqualValues(t, len(content), w.Dropped())
+	assert.NoError(t, os.Remove(path))
+	advance(10 * time.Second)
+	checkWrite(t, w)
+	assert.NoError(t, w.Failure())
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^recovered from log file failure, \\d+ bytes were not written: .+\n"+content+"$", string(data))
+}
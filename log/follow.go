@@ -0,0 +1,380 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FollowOptions configures a Follower.
+type FollowOptions struct {
+	// From and To restrict returned entries to [From, To), zero values
+	// leave the range open. Lines without timestamp, like continuation
+	// lines or collapsed counts, follow the entry they belong to.
+	From time.Time
+	To   time.Time
+	// Follow keeps waiting for new entries once the current log file has
+	// been read, like "tail -f".
+	Follow bool
+	// PollInterval defaults to 200 milliseconds.
+	PollInterval time.Duration
+	// Naming must match the RotateWriter one, it defaults to TimeNaming.
+	Naming NamingScheme
+}
+
+type followed struct {
+	filename string
+	skip     int64
+}
+
+// Follower reads a log file written by a RotateWriter, starting with its
+// rotated files in chronological order, and optionally keeps following the
+// current file across rotations. The current file is only kept open while
+// being read so that it never prevents its rotation. Rotated files which
+// cannot be read are skipped and reported by Skipped.
+type Follower struct {
+	// mutex is held by Next so that Close can release the current file
+	// once Next returned.
+	mutex    sync.Mutex
+	filename string
+	options  FollowOptions
+	pending  []followed
+	// seen holds the uncompressed names of listed rotated files.
+	seen    map[string]bool
+	reader  *bufio.Reader
+	current io.ReadCloser
+	// name is the file being read.
+	name string
+	// offset is the number of bytes read from the current file, live is
+	// true once reading it and info identifies it.
+	live    bool
+	offset  int64
+	info    os.FileInfo
+	window  timeWindow
+	done    chan struct{}
+	closing sync.Once
+	// skipped holds the errors of rotated files which could not be read.
+	skipped []error
+}
+
+// NewFollower creates a Follower on the log file filename.
+func NewFollower(filename string, options FollowOptions) (*Follower, error) {
+	if options.PollInterval <= 0 {
+		options.PollInterval = 200 * time.Millisecond
+	}
+	if options.Naming == nil {
+		options.Naming = TimeNaming{}
+	}
+	f := &Follower{
+		filename: filename,
+		options:  options,
+		seen:     map[string]bool{},
+		window:   newTimeWindow(options.From, options.To),
+		done:     make(chan struct{}),
+	}
+	history, err := readableHistory(filename, options.Naming, options.From, f.seen)
+	if err != nil {
+		return nil, err
+	}
+	for _, rotated := range history {
+		f.pending = append(f.pending, followed{filename: rotated})
+	}
+	return f, nil
+}
+
+// readableHistory returns the rotated files of filename to read, oldest
+// first, skipping the ones last written before from unless it is zero.
+// Files being compressed are listed once, the uncompressed names of listed
+// and skipped files being added to seen.
+func readableHistory(filename string, scheme NamingScheme, from time.Time,
+	seen map[string]bool) ([]string, error) {
+	history, err := listRotated(filename, nil, scheme)
+	if err != nil {
+		return nil, err
+	}
+	readable := []string{}
+	for _, rotated := range history {
+		name := uncompressedName(rotated)
+		if seen[name] {
+			// Being compressed.
+			continue
+		}
+		seen[name] = true
+		if !from.IsZero() {
+			info, err := os.Stat(rotated)
+			// Skip files last written before the requested range.
+			if err != nil || info.ModTime().Before(from) {
+				continue
+			}
+		}
+		readable = append(readable, rotated)
+	}
+	return readable, nil
+}
+
+// Close stops following, pending and future Next calls return io.EOF. It
+// can be called concurrently with Next.
+func (f *Follower) Close() error {
+	f.closing.Do(func() {
+		close(f.done)
+	})
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.closeCurrent()
+}
+
+func (f *Follower) closeCurrent() error {
+	f.reader = nil
+	if f.current != nil {
+		err := f.current.Close()
+		f.current = nil
+		return err
+	}
+	return nil
+}
+
+func (f *Follower) openRotated() error {
+	next := f.pending[0]
+	f.pending = f.pending[1:]
+	r, err := openLogFile(next.filename)
+	if os.IsNotExist(err) {
+		// Pruned in the meantime.
+		return nil
+	}
+	if err != nil {
+		// Like archives in an unsupported format.
+		f.skipped = append(f.skipped, err)
+		return nil
+	}
+	_, err = io.CopyN(ioutil.Discard, r, next.skip)
+	if err != nil && err != io.EOF {
+		r.Close()
+		f.skipped = append(f.skipped, &os.PathError{Op: "read", Path: next.filename, Err: err})
+		return nil
+	}
+	f.current = r
+	f.name = next.filename
+	f.reader = bufio.NewReader(r)
+	return nil
+}
+
+// Skipped returns the errors of the rotated files which could not be read
+// entirely, like archives in an unsupported format, and were skipped.
+func (f *Follower) Skipped() []error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]error(nil), f.skipped...)
+}
+
+// openLive opens the current log file at the last read offset. It returns
+// false if the file does not exist, like between a rotation and the next
+// write.
+func (f *Follower) openLive() (bool, error) {
+	file, err := os.Open(f.filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return false, err
+	}
+	if f.info != nil && !os.SameFile(f.info, info) {
+		file.Close()
+		f.rotated()
+		return false, nil
+	}
+	if info.Size() < f.offset {
+		// Truncated.
+		f.offset = 0
+	}
+	_, err = file.Seek(f.offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return false, err
+	}
+	f.live = true
+	f.info = info
+	f.current = file
+	f.name = f.filename
+	f.reader = bufio.NewReader(file)
+	return true, nil
+}
+
+// rotated queues the files rotated since the last listing, the first one
+// being the previously followed file.
+func (f *Follower) rotated() {
+	history, _ := listRotated(f.filename, nil, f.options.Naming)
+	skip := f.offset
+	for _, rotated := range history {
+		name := uncompressedName(rotated)
+		if f.seen[name] {
+			continue
+		}
+		f.seen[name] = true
+		f.pending = append(f.pending, followed{filename: rotated, skip: skip})
+		skip = 0
+	}
+	f.live = false
+	f.info = nil
+	f.offset = 0
+}
+
+func (f *Follower) wait() bool {
+	select {
+	case <-f.done:
+		return false
+	case <-time.After(f.options.PollInterval):
+		return true
+	}
+}
+
+// Next returns the next log line without its line feed, or io.EOF once
+// the log has been read entirely, the time range exceeded or the follower
+// closed.
+func (f *Follower) Next() (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for {
+		if f.window.finished {
+			return "", io.EOF
+		}
+		select {
+		case <-f.done:
+			return "", io.EOF
+		default:
+		}
+		if f.reader != nil {
+			line, err := f.reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				if f.live {
+					return "", err
+				}
+				// Corrupted rotated files are skipped from there.
+				f.skipped = append(f.skipped, &os.PathError{Op: "read", Path: f.name, Err: err})
+				f.closeCurrent()
+				continue
+			}
+			complete := err == nil
+			if f.live && !complete && f.options.Follow {
+				// Wait for the end of the line.
+				line = ""
+			}
+			if !complete {
+				f.closeCurrent()
+			}
+			if line == "" {
+				continue
+			}
+			if f.live {
+				f.offset += int64(len(line))
+			}
+			if f.window.accept(line) {
+				return strings.TrimRight(line, "\r\n"), nil
+			}
+			continue
+		}
+		if len(f.pending) > 0 {
+			err := f.openRotated()
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		if !f.live || f.options.Follow {
+			if f.live && !f.wait() {
+				return "", io.EOF
+			}
+			opened, err := f.openLive()
+			if err != nil {
+				return "", err
+			}
+			if opened || len(f.pending) > 0 {
+				continue
+			}
+			if !f.options.Follow {
+				return "", io.EOF
+			}
+			if !f.wait() {
+				return "", io.EOF
+			}
+			continue
+		}
+		return "", io.EOF
+	}
+}
+
+// timeWindow restricts log lines to [from, to), zero values leaving the
+// range open. Lines without timestamp follow the entry they belong to.
+type timeWindow struct {
+	from     time.Time
+	to       time.Time
+	inRange  bool
+	finished bool
+}
+
+func newTimeWindow(from, to time.Time) timeWindow {
+	return timeWindow{from: from, to: to, inRange: from.IsZero()}
+}
+
+// accept applies the time range to line and updates the range state.
+func (w *timeWindow) accept(line string) bool {
+	if w.from.IsZero() && w.to.IsZero() {
+		return true
+	}
+	t, ok := parseTimestamp(line)
+	if !ok {
+		return w.inRange
+	}
+	if !w.to.IsZero() && !t.Before(w.to) {
+		w.finished = true
+		return false
+	}
+	w.inRange = !t.Before(w.from)
+	return w.inRange
+}
+
+// parseTimestamp extracts the time of TimeWriter and JSONWriter entries.
+// TimeWriter stamps with custom layouts or elapsed times are not supported.
+func parseTimestamp(line string) (time.Time, bool) {
+	if strings.HasPrefix(line, "{") {
+		e := struct {
+			Time time.Time `json:"time"`
+		}{}
+		err := json.Unmarshal([]byte(line), &e)
+		return e.Time, err == nil && !e.Time.IsZero()
+	}
+	if !strings.HasPrefix(line, "[") {
+		return time.Time{}, false
+	}
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return time.Time{}, false
+	}
+	// Fractional seconds are accepted by the default layouts.
+	layouts := []string{DefaultTimeLayout, DefaultTimeLayout + zoneLayout, time.RFC3339Nano}
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, line[1:end], time.Local)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,149 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, f *Follower) []string {
+	lines := []string{}
+	for {
+		line, err := f.Next()
+		if err == io.EOF {
+			return lines
+		}
+		assert.NoError(t, err)
+		lines = append(lines, line)
+	}
+}
+
+func writeGzip(t *testing.T, filename, text string) {
+	file, err := os.Create(filename)
+	assert.NoError(t, err)
+	defer file.Close()
+	w := gzip.NewWriter(file)
+	_, err = w.Write([]byte(text))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+}
+
+func TestFollowerReplaysRotatedFilesInOrder(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	writeGzip(t, filepath.Join(dir, rotated1+".gz"), "first\nsecond\n")
+	err := os.Remove(filepath.Join(dir, rotated1))
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, rotated2), []byte("third\n"), os.ModePerm)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, filename), []byte("fourth\nfifth"), os.ModePerm)
+	assert.NoError(t, err)
+	f, err := NewFollower(filepath.Join(dir, filename), FollowOptions{})
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"first", "second", "third", "fourth", "fifth"}, readAll(t, f))
+}
+
+func TestFollowerSkipsUnreadableRotatedFiles(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	zstd := filepath.Join(dir, "filename.20130916T115500.log.zst")
+	invalid := filepath.Join(dir, "filename.20130916T115501.log.gz")
+	truncated := filepath.Join(dir, "filename.20130916T115502.log.gz")
+	assert.NoError(t, ioutil.WriteFile(zstd, []byte("zstd data"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(invalid, []byte("not a gzip file"), os.ModePerm))
+	writeGzip(t, truncated, "first\nsecond\n")
+	data, err := ioutil.ReadFile(truncated)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(truncated, data[:len(data)-4], os.ModePerm))
+	err = ioutil.WriteFile(filepath.Join(dir, filename), []byte("third\n"), os.ModePerm)
+	assert.NoError(t, err)
+	f, err := NewFollower(filepath.Join(dir, filename), FollowOptions{})
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"first", "second", "third"}, readAll(t, f))
+	skipped := []string{}
+	for _, err := range f.Skipped() {
+		skipped = append(skipped, err.Error())
+	}
+	assert.Equal(t, []string{
+		"open " + zstd + ": unsupported compression",
+		"open " + invalid + ": gzip: invalid header",
+		"read " + truncated + ": unexpected EOF",
+	}, skipped)
+}
+
+func TestFollowerFollowsRotations(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 2, "lines", true)
+	assert.NoError(t, err)
+	defer w.Close()
+	f, err := NewFollower(filepath.Join(dir, filename), FollowOptions{
+		Follow:       true,
+		PollInterval: time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer f.Close()
+	next := func() string {
+		line, err := f.Next()
+		assert.NoError(t, err)
+		return line
+	}
+	_, err = w.Write([]byte("1\n2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", next())
+	for _, line := range []string{"\n", "3\n", "4\n", "5\n", "6\n"} {
+		_, err = w.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.Len(t, readFiles(t, dir), 3)
+	for _, expected := range []string{"2", "3", "4", "5", "6"} {
+		assert.Equal(t, expected, next())
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		f.Close()
+	}()
+	_, err = f.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFollowerFiltersTimeRange(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	err := ioutil.WriteFile(filepath.Join(dir, filename), []byte(
+		"[2016-03-14 09:59:59] before\n"+
+			"continued before\n"+
+			"[2016-03-14 10:00:00] first\n"+
+			"continued first\n"+
+			"[2016-03-14 10:30:00]  ...x3\n"+
+			"[2016-03-14 11:00:00] after\n"+
+			"[2016-03-14 10:40:00] ignored\n"), os.ModePerm)
+	assert.NoError(t, err)
+	f, err := NewFollower(filepath.Join(dir, filename), FollowOptions{
+		From: time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local),
+		To:   time.Date(2016, 3, 14, 11, 0, 0, 0, time.Local),
+	})
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{
+		"[2016-03-14 10:00:00] first",
+		"continued first",
+		"[2016-03-14 10:30:00]  ...x3",
+	}, readAll(t, f))
+}
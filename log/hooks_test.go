@@ -0,0 +1,76 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingLogCallsHooks(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	events := []string{}
+	w, err := NewRotateWriter(log, 2, 2, "lines", true,
+		OnOpen(func(w io.Writer) {
+			events = append(events, "open")
+			fmt.Fprintln(w, "header")
+		}),
+		OnRotate(func(oldPath, newPath string) {
+			events = append(events, "rotate "+filepath.Base(oldPath)+" "+filepath.Base(newPath))
+		}),
+		OnPrune(func(path string) {
+			events = append(events, "prune "+filepath.Base(path))
+		}))
+	assert.NoError(t, err)
+	defer w.Close()
+	for i := 0; i < 3; i++ {
+		checkWriteLine(t, w)
+		advance(time.Second)
+	}
+	assert.Equal(t, []string{"filename.20160314T100002.log", filename}, readFiles(t, dir))
+	checkContent(t, log, "header\n"+someline)
+	checkContent(t, filepath.Join(dir, "filename.20160314T100002.log"), "header\n"+someline)
+	assert.Equal(t, []string{
+		"open",
+		"rotate filename.log filename.20160314T100001.log",
+		"open",
+		"rotate filename.log filename.20160314T100002.log",
+		"prune filename.20160314T100001.log",
+		"open",
+	}, events)
+}
+
+func TestRotatingLogCallsCompressHook(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	compressed := make(chan string, 1)
+	w, err := NewRotateWriter(log, -1, 1, "lines", true,
+		WithCompression(GzipCompressor{}),
+		OnCompress(func(path, archive string) {
+			assert.Equal(t, path+".gz", archive)
+			compressed <- archive
+		}))
+	assert.NoError(t, err)
+	checkWriteLine(t, w)
+	checkWriteLine(t, w)
+	assert.NoError(t, w.Close())
+	archive := <-compressed
+	checkGzipContent(t, archive, someline)
+}
@@ -0,0 +1,243 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a log entry parsed from the standard log package output or from
+// a Logger.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Prefix  string    `json:"prefix,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message"`
+	Fields  Fields    `json:"fields,omitempty"`
+	Repeat  int       `json:"repeat,omitempty"`
+}
+
+// Field is a key/value pair of a log entry.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Fields are the key/value pairs of a log entry, in order. They are encoded
+// as a JSON object keeping that order.
+type Fields []Field
+
+func (f Fields) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, field := range f {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (f *Fields) UnmarshalJSON(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		*f = nil
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("invalid log entry fields: %s", data)
+	}
+	fields := Fields{}
+	for d.More() {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		field := Field{Key: token.(string)}
+		err = d.Decode(&field.Value)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, field)
+	}
+	*f = fields
+	return nil
+}
+
+// splitTokens splits a line on spaces, keeping quoted strings whole.
+func splitTokens(line string) []string {
+	tokens := []string{}
+	start := -1
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+			if start < 0 {
+				start = i
+			}
+		case c == ' ' && !quoted:
+			if start >= 0 {
+				tokens = append(tokens, line[start:i])
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, line[start:])
+	}
+	return tokens
+}
+
+// parseField splits a key=value token, unquoting the value if necessary.
+func parseField(token string) (string, string, bool) {
+	i := strings.IndexByte(token, '=')
+	if i <= 0 || strings.ContainsAny(token[:i], "\" ") {
+		return "", "", false
+	}
+	key, value := token[:i], token[i+1:]
+	if strings.HasPrefix(value, "\"") {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", "", false
+		}
+		value = unquoted
+	}
+	return key, value, true
+}
+
+func isLevel(s string) bool {
+	for _, name := range levelNames {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEntry splits a log line formatted as "<prefix> LEVEL message
+// key=value..." into an Entry. The prefix and level are optional. Trailing
+// key=value tokens of Logger entries, which have a level, become fields.
+// Other messages are kept verbatim.
+func ParseEntry(line string) Entry {
+	e := Entry{}
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "<") {
+		if i := strings.Index(line, "> "); i > 0 {
+			e.Prefix = line[1:i]
+			line = line[i+2:]
+		}
+	}
+	if i := strings.IndexByte(line, ' '); i > 0 && isLevel(line[:i]) {
+		e.Level = line[:i]
+		line = line[i+1:]
+	} else if isLevel(line) {
+		e.Level = line
+		line = ""
+	}
+	if e.Level == "" || strings.ContainsRune(line, '\n') {
+		e.Message = line
+		return e
+	}
+	tokens := splitTokens(line)
+	first := len(tokens)
+	for first > 0 {
+		if _, _, ok := parseField(tokens[first-1]); !ok {
+			break
+		}
+		first--
+	}
+	if first < len(tokens) {
+		for _, token := range tokens[first:] {
+			key, value, _ := parseField(token)
+			e.Fields = append(e.Fields, Field{key, value})
+		}
+		// Cut the message before the first field, preserving its spacing.
+		index := len(line)
+		for i := len(tokens) - 1; i >= first; i-- {
+			index = strings.LastIndex(line[:index], tokens[i])
+		}
+		line = strings.TrimRight(line[:index], " ")
+	}
+	e.Message = line
+	return e
+}
+
+// JSONWriter writes every log entry as a single line JSON object holding
+// its timestamp, prefix, level, message and fields.
+type JSONWriter struct {
+	w io.Writer
+}
+
+// NewJSONWriter returns a JSONWriter writing to w.
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: w}
+}
+
+func (w *JSONWriter) writeEntry(e Entry, size int) (int, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	_, err = w.w.Write(append(data, '\n'))
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (w *JSONWriter) Write(p []byte) (int, error) {
+	e := ParseEntry(string(p))
+	e.Time = now()
+	return w.writeEntry(e, len(p))
+}
+
+// WriteRepeat writes entry p again with its repeat count, so that collapsed
+// entries keep their content.
+func (w *JSONWriter) WriteRepeat(p []byte, count int) (int, error) {
+	e := ParseEntry(string(p))
+	e.Time = now()
+	e.Repeat = count
+	return w.writeEntry(e, len(p))
+}
+
+// MakeJSONCollapsingWriter is the JSON equivalent of MakeCollapsingWriter.
+func MakeJSONCollapsingWriter(w io.Writer, options ...CollapseOption) *CollapsingWriter {
+	return NewCollapsingWriter(NewJSONWriter(w), options...)
+}
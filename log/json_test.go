@@ -0,0 +1,105 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseEntry(t *testing.T) {
+	assert.Equal(t, Entry{
+		Prefix:  "simulation",
+		Level:   "INFO",
+		Message: "log settings",
+		Fields: Fields{
+			{"max-files", "-1"},
+			{"log", "some file.log"},
+		},
+	}, ParseEntry("<simulation> INFO log settings max-files=-1 log=\"some file.log\"\n"))
+	assert.Equal(t, Entry{
+		Prefix:  "simulation",
+		Message: "command line [sim.exe -a b]",
+	}, ParseEntry("<simulation> command line [sim.exe -a b]\n"))
+	assert.Equal(t, Entry{
+		Level:   "WARN",
+		Message: "key=value in  the middle",
+		Fields:  Fields{{"end", "1"}},
+	}, ParseEntry("WARN key=value in  the middle end=1"))
+	// Fields are only extracted from Logger entries.
+	assert.Equal(t, Entry{
+		Prefix:  "app",
+		Message: "command line [sim -log=out.log -max-files=3]",
+	}, ParseEntry("<app> command line [sim -log=out.log -max-files=3]\n"))
+	assert.Equal(t, Entry{
+		Message: "fetching http://host/api?id=42",
+	}, ParseEntry("fetching http://host/api?id=42"))
+	assert.Equal(t, Entry{
+		Level:   "ERROR",
+		Message: "panic: oops\nstack",
+	}, ParseEntry("ERROR panic: oops\nstack\n"))
+}
+
+func TestFieldsKeepTheirOrderInJSON(t *testing.T) {
+	e := Entry{Message: "m", Fields: Fields{{"z", "1"}, {"a", "\"2\""}}}
+	data, err := json.Marshal(e)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"time":"0001-01-01T00:00:00Z","message":"m","fields":{"z":"1","a":"\"2\""}}`, string(data))
+	parsed := Entry{}
+	assert.NoError(t, json.Unmarshal(data, &parsed))
+	assert.Equal(t, e, parsed)
+	assert.NoError(t, json.Unmarshal([]byte(`{"message":"m","fields":null}`), &parsed))
+	assert.Nil(t, parsed.Fields)
+	assert.Error(t, json.Unmarshal([]byte(`{"fields":[]}`), &parsed))
+}
+
+func TestJSONWriterWritesOneObjectPerEntry(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.UTC))
+	defer reset()
+	b := bytes.Buffer{}
+	w := MakeJSONCollapsingWriter(&b)
+	m := []byte("<app> WARN lost contact unit=12\n")
+	for i := 0; i < 3; i++ {
+		n, err := w.Write(m)
+		assert.NoError(t, err)
+		if i == 0 {
+			assert.Equal(t, len(m), n)
+		}
+	}
+	_, err := w.Write([]byte("<app> done\n"))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"time":"2016-03-14T10:00:00Z","prefix":"app","level":"WARN","message":"lost contact","fields":{"unit":"12"}}`+"\n"+
+			`{"time":"2016-03-14T10:00:00Z","prefix":"app","level":"WARN","message":"lost contact","fields":{"unit":"12"},"repeat":3}`+"\n"+
+			`{"time":"2016-03-14T10:00:00Z","prefix":"app","message":"done"}`+"\n",
+		b.String())
+}
+
+func TestJSONWriterWritesToRotateWriter(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.UTC))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 1, "lines", true)
+	assert.NoError(t, err)
+	defer w.Close()
+	l := NewLogger(NewJSONWriter(w), "<app> ", LevelInfo)
+	l.Info("first")
+	l.Info("second")
+	files := readFiles(t, dir)
+	assert.Equal(t, []string{"filename.20160314T100000.log", filename}, files)
+	checkContent(t, filepath.Join(dir, files[1]),
+		`{"time":"2016-03-14T10:00:00Z","prefix":"app","level":"INFO","message":"second"}`+"\n")
+}
@@ -0,0 +1,145 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatingLogSharedBetweenWritersRotatesOnce(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	w1, err := NewRotateWriter(log, -1, 2, "bytes", false, WithFileLock())
+	assert.NoError(t, err)
+	defer w1.Close()
+	w2, err := NewRotateWriter(log, -1, 2, "bytes", false, WithFileLock())
+	assert.NoError(t, err)
+	defer w2.Close()
+	write := func(w *RotateWriter, text string) {
+		_, err := w.Write([]byte(text))
+		assert.NoError(t, err)
+		advance(time.Second)
+	}
+	write(w1, "a")
+	write(w2, "b")
+	write(w1, "c")
+	write(w2, "d")
+	write(w2, "e")
+	write(w1, "f")
+	assert.Equal(t, []string{
+		"filename.20160314T100002.log",
+		"filename.20160314T100004.log",
+		filename,
+		filename + ".lock",
+	}, readFiles(t, dir))
+	checkContent(t, filepath.Join(dir, "filename.20160314T100002.log"), "ab")
+	checkContent(t, filepath.Join(dir, "filename.20160314T100004.log"), "cd")
+	checkContent(t, log, "ef")
+}
+
+func testSharedPruning(t *testing.T, naming NamingScheme) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	const maxSize = 32
+	mutex := sync.Mutex{}
+	rotated := []string{}
+	pruned := []string{}
+	options := []RotateOption{
+		WithFileLock(),
+		WithNaming(naming),
+		// Hooks are called while holding the shared lock, in the order of
+		// the operations on disk.
+		OnRotate(func(oldPath, newPath string) {
+			// A file just opened by another writer must not be rotated.
+			info, err := os.Stat(newPath)
+			if assert.NoError(t, err) {
+				assert.True(t, info.Size() >= maxSize, "%s: %d bytes", newPath, info.Size())
+			}
+			mutex.Lock()
+			rotated = append(rotated, newPath)
+			mutex.Unlock()
+		}),
+		OnPrune(func(path string) {
+			mutex.Lock()
+			pruned = append(pruned, path)
+			mutex.Unlock()
+		}),
+	}
+	writers := []*RotateWriter{}
+	for i := 0; i < 4; i++ {
+		w, err := NewRotateWriter(log, 3, maxSize, "bytes", false, options...)
+		assert.NoError(t, err)
+		writers = append(writers, w)
+	}
+	wg := sync.WaitGroup{}
+	for i, w := range writers {
+		wg.Add(1)
+		go func(i int, w *RotateWriter) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, err := fmt.Fprintf(w, "%d %02d\n", i, j)
+				assert.NoError(t, err)
+			}
+		}(i, w)
+	}
+	wg.Wait()
+	for _, w := range writers {
+		assert.NoError(t, w.Close())
+	}
+	// Only the two last rotated files are kept, the others are pruned
+	// oldest first, once.
+	if !assert.True(t, len(rotated) > 2, len(rotated)) {
+		return
+	}
+	kept := rotated[len(rotated)-2:]
+	assert.Equal(t, rotated[:len(rotated)-2], pruned)
+	files := []string{filepath.Base(log), filepath.Base(log) + ".lock"}
+	for _, name := range kept {
+		files = append(files, filepath.Base(name))
+	}
+	assert.ElementsMatch(t, files, readFiles(t, dir))
+	// The remaining files hold the last lines of writers, in order and
+	// without gaps: those of a writer end with its last line.
+	last := map[int]int{}
+	for _, name := range append(kept, log) {
+		data, err := ioutil.ReadFile(name)
+		assert.NoError(t, err)
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			i, j := 0, 0
+			_, err := fmt.Sscanf(line, "%d %d", &i, &j)
+			assert.NoError(t, err, line)
+			if previous, ok := last[i]; ok {
+				assert.Equal(t, previous+1, j, "%s: %s", name, line)
+			}
+			last[i] = j
+		}
+	}
+	assert.NotEmpty(t, last)
+	for i, j := range last {
+		assert.Equal(t, 49, j, "writer %d", i)
+	}
+}
+
+func TestRotatingLogSharedBetweenWritersPrunesOnce(t *testing.T) {
+	testSharedPruning(t, TimeNaming{})
+	testSharedPruning(t, NumericNaming{})
+}
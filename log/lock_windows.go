@@ -0,0 +1,45 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	lockfileExclusiveLock = 2
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+func lockFile(f *os.File) error {
+	ol := &syscall.Overlapped{}
+	ret, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock, 0, 1, 0,
+		uintptr(unsafe.Pointer(ol)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	ol := &syscall.Overlapped{}
+	ret, _, err := procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(ol)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
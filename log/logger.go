@@ -0,0 +1,218 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Level is the severity of a log entry.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+func (l Level) String() string {
+	if l >= LevelDebug && l <= LevelError {
+		return levelNames[l]
+	}
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLevel converts a case insensitive level name into a Level.
+func ParseLevel(value string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(value, name) {
+			return Level(i), nil
+		}
+	}
+	if strings.EqualFold(value, "warning") {
+		return LevelWarn, nil
+	}
+	return LevelInfo, fmt.Errorf("invalid log level: %q", value)
+}
+
+// Logger writes leveled entries made of a message and key/value fields, like:
+//
+//	<prefix> INFO message key=value other="quoted value"
+//
+// Each entry is sent to the underlying writer with a single Write call, so
+// that it can go through a CollapsingWriter and a RotateWriter. Loggers
+// derived with With share their level with their parent.
+type Logger struct {
+	w      io.Writer
+	prefix string
+	level  *int32
+	fields []byte
+}
+
+// NewLogger creates a logger writing entries of at least the supplied level
+// to w, each entry starting with prefix. A nil writer sends entries to the
+// standard log package output, with its prefix.
+func NewLogger(w io.Writer, prefix string, level Level) *Logger {
+	l := int32(level)
+	return &Logger{
+		w:      w,
+		prefix: prefix,
+		level:  &l,
+	}
+}
+
+// SetLevel changes the minimum level of logged entries. It is safe to call
+// while other goroutines are logging.
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreInt32(l.level, int32(level))
+}
+
+// Level returns the minimum level of logged entries.
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(l.level))
+}
+
+// Enabled returns true if entries of the supplied level are logged.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// With returns a child logger adding the supplied key/value pairs to every
+// entry.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	child := *l
+	buf := bytes.NewBuffer(append([]byte(nil), l.fields...))
+	appendFields(buf, keyvals)
+	child.fields = buf.Bytes()
+	return &child
+}
+
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.Log(LevelDebug, msg, keyvals...)
+}
+
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.Log(LevelInfo, msg, keyvals...)
+}
+
+func (l *Logger) Warn(msg string, keyvals ...interface{}) {
+	l.Log(LevelWarn, msg, keyvals...)
+}
+
+func (l *Logger) Error(msg string, keyvals ...interface{}) {
+	l.Log(LevelError, msg, keyvals...)
+}
+
+// Log writes an entry if level is enabled. keyvals alternate keys and
+// values, a missing trailing value is reported as such.
+func (l *Logger) Log(level Level, msg string, keyvals ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	w, prefix := l.w, l.prefix
+	if w == nil {
+		w, prefix = log.Writer(), log.Prefix()
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString(prefix)
+	buf.WriteString(level.String())
+	buf.WriteByte(' ')
+	buf.WriteString(msg)
+	buf.Write(l.fields)
+	appendFields(buf, keyvals)
+	buf.WriteByte('\n')
+	w.Write(buf.Bytes())
+}
+
+func appendFields(buf *bytes.Buffer, keyvals []interface{}) {
+	for i := 0; i < len(keyvals); i += 2 {
+		buf.WriteByte(' ')
+		buf.WriteString(formatValue(keyvals[i]))
+		buf.WriteByte('=')
+		if i+1 < len(keyvals) {
+			buf.WriteString(formatValue(keyvals[i+1]))
+		} else {
+			buf.WriteString("(MISSING)")
+		}
+	}
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+var std = NewLogger(nil, "", LevelInfo)
+
+// Default returns the logger writing to the standard log package output.
+func Default() *Logger {
+	return std
+}
+
+// SetLevel changes the minimum level of the default logger and its children.
+func SetLevel(level Level) {
+	std.SetLevel(level)
+}
+
+// With returns a child of the default logger.
+func With(keyvals ...interface{}) *Logger {
+	return std.With(keyvals...)
+}
+
+func Debug(msg string, keyvals ...interface{}) {
+	std.Log(LevelDebug, msg, keyvals...)
+}
+
+func Info(msg string, keyvals ...interface{}) {
+	std.Log(LevelInfo, msg, keyvals...)
+}
+
+func Warn(msg string, keyvals ...interface{}) {
+	std.Log(LevelWarn, msg, keyvals...)
+}
+
+func Error(msg string, keyvals ...interface{}) {
+	std.Log(LevelError, msg, keyvals...)
+}
@@ -0,0 +1,89 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+)
+
+func TestLoggerFormatsEntries(t *testing.T) {
+	b := bytes.Buffer{}
+	l := NewLogger(&b, "<test> ", LevelDebug)
+	l.Info("started", "max-files", -1, "log", "some file.log", "err", errors.New("failed"))
+	assert.Equal(t, "<test> INFO started max-files=-1 log=\"some file.log\" err=failed\n", b.String())
+	b.Reset()
+	l.Warn("odd", "key")
+	assert.Equal(t, "<test> WARN odd key=(MISSING)\n", b.String())
+	b.Reset()
+	l.Debug("empty", "value", "")
+	assert.Equal(t, "<test> DEBUG empty value=\"\"\n", b.String())
+}
+
+func TestLoggerFiltersLevels(t *testing.T) {
+	b := bytes.Buffer{}
+	l := NewLogger(&b, "", LevelWarn)
+	child := l.With("unit", 12)
+	l.Info("hidden")
+	child.Info("hidden")
+	child.Error("shown")
+	assert.Equal(t, "ERROR shown unit=12\n", b.String())
+	b.Reset()
+	l.SetLevel(LevelDebug)
+	assert.True(t, child.Enabled(LevelDebug))
+	child.With("tick", 3).Debug("shown", "extra", true)
+	assert.Equal(t, "DEBUG shown unit=12 tick=3 extra=true\n", b.String())
+}
+
+func TestLoggerCollapsesRepeatedEntries(t *testing.T) {
+	b := bytes.Buffer{}
+	l := NewLogger(&CollapsingWriter{w: &b}, "", LevelInfo)
+	l.Info("lost contact", "unit", 12)
+	l.Info("lost contact", "unit", 12)
+	l.Info("done")
+	assert.Equal(t, "INFO lost contact unit=12\n ...x2\nINFO done\n", b.String())
+}
+
+func TestDefaultLoggerUsesStandardLogOutput(t *testing.T) {
+	b := bytes.Buffer{}
+	writer, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	defer func() {
+		log.SetOutput(writer)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+		SetLevel(LevelInfo)
+	}()
+	log.SetOutput(&b)
+	log.SetPrefix("<app> ")
+	SetLevel(LevelWarn)
+	Info("hidden")
+	With("key", "value").Warn("shown")
+	assert.Equal(t, "<app> WARN shown key=value\n", b.String())
+}
+
+func TestParseLevel(t *testing.T) {
+	for value, expected := range map[string]Level{
+		"debug":   LevelDebug,
+		"INFO":    LevelInfo,
+		"Warn":    LevelWarn,
+		"warning": LevelWarn,
+		"error":   LevelError,
+	} {
+		level, err := ParseLevel(value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, level)
+	}
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+	assert.Equal(t, "LEVEL(7)", Level(7).String())
+}
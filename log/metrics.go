@@ -0,0 +1,77 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Metrics gathers the counters of the writers of a log pipeline, any of
+// which may be nil. Values can be published with expvar:
+//
+//	expvar.Publish("log", expvar.Func(func() interface{} { return m.Values() }))
+type Metrics struct {
+	Rotate   *RotateWriter
+	Collapse *CollapsingWriter
+	Async    *AsyncWriter
+	Sampling *SamplingWriter
+}
+
+// Values returns the counters of the configured writers by name.
+func (m *Metrics) Values() map[string]int64 {
+	values := map[string]int64{}
+	if m.Rotate != nil {
+		s := m.Rotate.Stats()
+		values["bytes"] = s.Bytes
+		values["lines"] = s.Lines
+		values["rotations"] = s.Rotations
+		values["prunes"] = s.Prunes
+		values["errors"] = s.Errors
+		values["dropped_bytes"] = s.Dropped
+		values["failing"] = 0
+		if m.Rotate.Failure() != nil {
+			values["failing"] = 1
+		}
+	}
+	if m.Collapse != nil {
+		s := m.Collapse.Stats()
+		values["entries"] = s.Entries
+		values["collapsed"] = s.Collapsed
+		values["collapse_summaries"] = s.Summaries
+	}
+	if m.Async != nil {
+		values["queue_depth"] = int64(m.Async.Pending())
+		values["queue_dropped"] = m.Async.Dropped()
+	}
+	if m.Sampling != nil {
+		values["suppressed"] = m.Sampling.Suppressed()
+	}
+	return values
+}
+
+// WritePrometheus writes the counters in the Prometheus text format, their
+// names starting with prefix followed by an underscore.
+func (m *Metrics) WritePrometheus(w io.Writer, prefix string) error {
+	values := m.Values()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, err := fmt.Fprintf(w, "%s_%s %d\n", prefix, name, values[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMetricsCountWriterActivity(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 2, int64(len(someline)), "bytes", true)
+	assert.NoError(t, err)
+	a := NewAsyncWriter(w, 10, false)
+	defer a.Close()
+	c := NewCollapsingWriter(a)
+	s := NewSamplingWriter(c, 0, 0, WithSummaryInterval(0))
+	m := &Metrics{Rotate: w, Collapse: c, Async: a}
+	for i := 0; i < 3; i++ {
+		c.Write([]byte(someline))
+		c.Write([]byte("other line\n"))
+	}
+	assert.NoError(t, c.Flush())
+	assert.NoError(t, a.Flush())
+	assert.Equal(t, map[string]int64{
+		"bytes":              63,
+		"lines":              6,
+		"rotations":          5,
+		"prunes":             4,
+		"errors":             0,
+		"dropped_bytes":      0,
+		"failing":            0,
+		"entries":            6,
+		"collapsed":          0,
+		"collapse_summaries": 0,
+		"queue_depth":        0,
+		"queue_dropped":      0,
+	}, m.Values())
+	c.Write([]byte(someline))
+	c.Write([]byte(someline))
+	s.Write([]byte(someline))
+	c.Flush()
+	m = &Metrics{Collapse: c, Sampling: s}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, m.WritePrometheus(buf, "log"))
+	assert.Equal(t, "log_collapse_summaries 1\nlog_collapsed 1\nlog_entries 8\nlog_suppressed 1\n", buf.String())
+}
+
+func TestRotateStatsCountFailures(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 0, "bytes", true, WithFallback(nil))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWriteLine(t, w)
+	w.mutex.Lock()
+	w.file.Close()
+	w.mutex.Unlock()
+	checkWriteLine(t, w)
+	stats := w.Stats()
+	assert.EqualValues(t, len(someline), stats.Bytes)
+	assert.EqualValues(t, 1, stats.Lines)
+	assert.EqualValues(t, 1, stats.Errors)
+	assert.EqualValues(t, len(someline), stats.Dropped)
+	m := &Metrics{Rotate: w}
+	assert.EqualValues(t, 1, m.Values()["failing"])
+}
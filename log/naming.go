@@ -0,0 +1,237 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NamingScheme names the rotated files of a log file and recognizes them.
+type NamingScheme interface {
+	// Rotated returns the name of filename rotated at t, given its rotated
+	// files oldest first.
+	Rotated(filename string, t time.Time, history []string) string
+	// Parse reports whether name, stripped of any compression extension,
+	// is a rotated file of filename and returns its rotation time and
+	// sequence number, which order rotated files.
+	Parse(filename, name string) (t time.Time, seq int, ok bool)
+}
+
+// DefaultLayout is the time layout of rotated files names used by default.
+const DefaultLayout = "20060102T150405"
+
+// TimeNaming names rotated files after their rotation time, like
+// "app.20060102T150405.log", with a ".N" suffix on collisions. Layout
+// defaults to DefaultLayout.
+type TimeNaming struct {
+	Layout string
+}
+
+func (n TimeNaming) layout() string {
+	if n.Layout == "" {
+		return DefaultLayout
+	}
+	return n.Layout
+}
+
+func (n TimeNaming) Rotated(filename string, t time.Time, history []string) string {
+	ext := filepath.Ext(filename)
+	rotated := strings.TrimSuffix(filename, ext) + "." + t.Format(n.layout()) + ext
+	// Collision suffixes keep increasing even when older files were pruned,
+	// so that the newest file sorts last.
+	suffix := 0
+	for _, name := range history {
+		name = uncompressedName(name)
+		if name == rotated && suffix == 0 {
+			suffix = 1
+		} else if strings.HasPrefix(name, rotated+".") {
+			seq, ok := parseSeq(name[len(rotated)+1:])
+			if ok && seq >= suffix {
+				suffix = seq + 1
+			}
+		}
+	}
+	to := rotated
+	if suffix > 0 {
+		to = rotated + "." + strconv.Itoa(suffix)
+	}
+	for exists(to, history) {
+		suffix++
+		to = rotated + "." + strconv.Itoa(suffix)
+	}
+	return to
+}
+
+func (n TimeNaming) Parse(filename, name string) (time.Time, int, bool) {
+	ext := filepath.Ext(filename)
+	rest, ok := trimStem(filename, name)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	seq := 0
+	for {
+		if strings.HasSuffix(rest, ext) {
+			t, err := time.ParseInLocation(n.layout(), strings.TrimSuffix(rest, ext), time.Local)
+			if err == nil {
+				return t, seq, true
+			}
+		}
+		// Strip a collision suffix and try again.
+		i := strings.LastIndex(rest, ".")
+		if i < 0 || seq != 0 {
+			return time.Time{}, 0, false
+		}
+		seq, ok = parseSeq(rest[i+1:])
+		if !ok {
+			return time.Time{}, 0, false
+		}
+		rest = rest[:i]
+	}
+}
+
+// NumericNaming names rotated files with increasing sequence numbers, like
+// "app.1.log" then "app.2.log". Numbers are never reused so that older files
+// keep their name, the oldest file having the lowest number.
+type NumericNaming struct{}
+
+func (n NumericNaming) Rotated(filename string, t time.Time, history []string) string {
+	last := 0
+	for _, rotated := range history {
+		_, seq, ok := n.Parse(filename, uncompressedName(rotated))
+		if ok && seq > last {
+			last = seq
+		}
+	}
+	ext := filepath.Ext(filename)
+	stem := strings.TrimSuffix(filename, ext)
+	to := ""
+	for seq := last + 1; to == "" || exists(to, history); seq++ {
+		to = stem + "." + strconv.Itoa(seq) + ext
+	}
+	return to
+}
+
+func (n NumericNaming) Parse(filename, name string) (time.Time, int, bool) {
+	ext := filepath.Ext(filename)
+	rest, ok := trimStem(filename, name)
+	if !ok || !strings.HasSuffix(rest, ext) {
+		return time.Time{}, 0, false
+	}
+	seq, ok := parseSeq(strings.TrimSuffix(rest, ext))
+	return time.Time{}, seq, ok
+}
+
+// SwitchedNaming names rotated files after Current and still recognizes
+// files named after the Previous schemes, most recent first, which were used
+// by earlier configurations. These files are older than the ones named after
+// Current, they are read and pruned first.
+type SwitchedNaming struct {
+	Current  NamingScheme
+	Previous []NamingScheme
+}
+
+func (n SwitchedNaming) Rotated(filename string, t time.Time, history []string) string {
+	return n.Current.Rotated(filename, t, history)
+}
+
+func (n SwitchedNaming) Parse(filename, name string) (time.Time, int, bool) {
+	for _, scheme := range n.schemes() {
+		t, seq, ok := scheme.Parse(filename, name)
+		if ok {
+			return t, seq, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// schemes returns Current then the Previous schemes.
+func (n SwitchedNaming) schemes() []NamingScheme {
+	return append([]NamingScheme{n.Current}, n.Previous...)
+}
+
+// ParseNamingScheme parses a naming scheme, either "numeric", "time" or an
+// empty string for TimeNaming with the default layout, or a custom TimeNaming
+// layout like "2006-01-02". It can be followed by the comma separated schemes
+// previously used, most recent first, giving a SwitchedNaming.
+func ParseNamingScheme(value string) (NamingScheme, error) {
+	values := strings.Split(value, ",")
+	if len(values) > 1 {
+		n := SwitchedNaming{}
+		for i, value := range values {
+			scheme, err := parseNamingScheme(value)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				n.Current = scheme
+			} else {
+				n.Previous = append(n.Previous, scheme)
+			}
+		}
+		return n, nil
+	}
+	return parseNamingScheme(value)
+}
+
+func parseNamingScheme(value string) (NamingScheme, error) {
+	switch value {
+	case "", "time":
+		return TimeNaming{}, nil
+	case "numeric":
+		return NumericNaming{}, nil
+	}
+	// The layout must produce valid file names which can be parsed back.
+	ref := time.Date(2013, 9, 16, 11, 55, 0, 0, time.Local)
+	formatted := ref.Format(value)
+	_, err := time.ParseInLocation(value, formatted, time.Local)
+	if err != nil || formatted == value || strings.ContainsAny(formatted, `/\:`) {
+		return nil, fmt.Errorf("invalid naming scheme: %q", value)
+	}
+	return TimeNaming{Layout: value}, nil
+}
+
+// trimStem returns name without the directory and stem of filename.
+func trimStem(filename, name string) (string, bool) {
+	if filepath.Dir(name) != filepath.Dir(filename) {
+		return "", false
+	}
+	base := filepath.Base(filename)
+	prefix := strings.TrimSuffix(base, filepath.Ext(base)) + "."
+	rest := filepath.Base(name)
+	if !strings.HasPrefix(rest, prefix) {
+		return "", false
+	}
+	return rest[len(prefix):], true
+}
+
+func parseSeq(value string) (int, bool) {
+	if value == "" || strings.Trim(value, "0123456789") != "" {
+		return 0, false
+	}
+	seq, err := strconv.Atoi(value)
+	return seq, err == nil && seq > 0
+}
+
+// exists reports whether name is already used by a rotated file, possibly
+// compressed, or any other file.
+func exists(name string, history []string) bool {
+	for _, rotated := range history {
+		if uncompressedName(rotated) == name {
+			return true
+		}
+	}
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,197 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNamingSchemesParseRotatedFiles(t *testing.T) {
+	ref := time.Date(2013, 9, 16, 11, 55, 0, 0, time.Local)
+	day := time.Date(2013, 9, 16, 0, 0, 0, 0, time.Local)
+	for _, c := range []struct {
+		scheme NamingScheme
+		name   string
+		t      time.Time
+		seq    int
+		ok     bool
+	}{
+		{TimeNaming{}, "filename.20130916T115500.log", ref, 0, true},
+		{TimeNaming{}, "filename.20130916T115500.log.2", ref, 2, true},
+		{TimeNaming{}, "filename.20130916T115500.log.2.3", time.Time{}, 0, false},
+		{TimeNaming{}, "filename.20130916T115500.txt", time.Time{}, 0, false},
+		{TimeNaming{}, "filename.log.state", time.Time{}, 0, false},
+		{TimeNaming{}, "filename.log", time.Time{}, 0, false},
+		{TimeNaming{}, "other.20130916T115500.log", time.Time{}, 0, false},
+		{TimeNaming{Layout: "2006-01-02"}, "filename.2013-09-16.log.1", day, 1, true},
+		{NumericNaming{}, "filename.12.log", time.Time{}, 12, true},
+		{NumericNaming{}, "filename.0.log", time.Time{}, 0, false},
+		{NumericNaming{}, "filename.20130916T115500.log", time.Time{}, 0, false},
+		{NumericNaming{}, "filename.log.lock", time.Time{}, 0, false},
+	} {
+		rt, seq, ok := c.scheme.Parse(filename, c.name)
+		assert.Equal(t, c.ok, ok, c.name)
+		assert.Equal(t, c.seq, seq, c.name)
+		assert.Equal(t, c.t, rt, c.name)
+	}
+}
+
+func TestParseNamingScheme(t *testing.T) {
+	for value, expected := range map[string]NamingScheme{
+		"":           TimeNaming{},
+		"time":       TimeNaming{},
+		"numeric":    NumericNaming{},
+		"2006-01-02": TimeNaming{Layout: "2006-01-02"},
+		"numeric,2006-01-02,time": SwitchedNaming{NumericNaming{},
+			[]NamingScheme{TimeNaming{Layout: "2006-01-02"}, TimeNaming{}}},
+	} {
+		scheme, err := ParseNamingScheme(value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, scheme, value)
+	}
+	for _, value := range []string{"sequence", "15:04", "2006/01/02", "numeric,15:04"} {
+		_, err := ParseNamingScheme(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRotatingLogSupportsNumericNaming(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 3, 1, "bytes", true,
+		WithNaming(NumericNaming{}))
+	assert.NoError(t, err)
+	defer w.Close()
+	for _, data := range []string{"a", "b", "c", "d"} {
+		_, err := w.Write([]byte(data))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"filename.2.log", "filename.3.log", filename}, readFiles(t, dir))
+	checkContent(t, filepath.Join(dir, "filename.2.log"), "b")
+	checkContent(t, filepath.Join(dir, "filename.3.log"), "c")
+	checkContent(t, filepath.Join(dir, filename), "d")
+}
+
+func TestRotatingLogSupportsCustomLayoutsAndExtensions(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	w, err := NewRotateWriter(filepath.Join(dir, "filename.txt"), -1, 1, "bytes", true,
+		WithNaming(TimeNaming{Layout: "2006-01-02"}))
+	assert.NoError(t, err)
+	for _, data := range []string{"a", "b", "c"} {
+		_, err := w.Write([]byte(data))
+		assert.NoError(t, err)
+	}
+	w.Close()
+	assert.Equal(t, []string{
+		"filename.2016-03-14.txt",
+		"filename.2016-03-14.txt.1",
+		"filename.txt",
+	}, readFiles(t, dir))
+	// Rotated files are found again on restart.
+	w, err = NewRotateWriter(filepath.Join(dir, "filename.txt"), 2, 1, "bytes", false,
+		WithNaming(TimeNaming{Layout: "2006-01-02"}))
+	assert.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, []string{"filename.2016-03-14.txt.1", "filename.txt"}, readFiles(t, dir))
+}
+
+func TestRotatingLogPrunesHistoryOfPreviousNaming(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	err := ioutil.WriteFile(filepath.Join(dir, filename), []byte(content), os.ModePerm)
+	assert.NoError(t, err)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 3, 3, "bytes", true,
+		WithNaming(SwitchedNaming{NumericNaming{}, []NamingScheme{TimeNaming{}}}))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWrite(t, w)
+	checkWrite(t, w)
+	// Time named files are older than numbered ones and pruned first.
+	assert.Equal(t, []string{"filename.1.log", "filename.2.log", unrelated1,
+		filename, unrelated2, unrelated3}, readFiles(t, dir))
+}
+
+func TestRotatingLogPrunesNumericHistoryAfterSwitchingToTime(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"filename.1.log", "filename.2.log", filename} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm)
+		assert.NoError(t, err)
+	}
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 2, 3, "bytes", true,
+		WithNaming(SwitchedNaming{TimeNaming{}, []NamingScheme{NumericNaming{}}}))
+	assert.NoError(t, err)
+	defer w.Close()
+	files := readFiles(t, dir)
+	assert.Len(t, files, 2)
+	assert.Regexp(t, created, files[0])
+	assert.Equal(t, filename, files[1])
+}
+
+func TestRotatingLogPrunesHistoryOfPreviousNamingSchemes(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"filename.2016-03-13.log", "filename.20160314T100000.log",
+		"filename.1.log", filename} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm)
+		assert.NoError(t, err)
+	}
+	scheme, err := ParseNamingScheme("numeric,time,2006-01-02")
+	assert.NoError(t, err)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 4, 3, "bytes", false, WithNaming(scheme))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWrite(t, w)
+	// Files of the oldest scheme are pruned first.
+	assert.Equal(t, []string{"filename.1.log", "filename.2.log", "filename.20160314T100000.log",
+		filename}, readFiles(t, dir))
+}
+
+func TestRotatingLogKeepsFilesOfOtherNamingSchemes(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"filename.1.log", "filename.2016-03-13.log", filename} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm)
+		assert.NoError(t, err)
+	}
+	w, err := NewRotateWriter(filepath.Join(dir, filename), 1, 3, "bytes", true)
+	assert.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, []string{"filename.1.log", "filename.2016-03-13.log", filename}, readFiles(t, dir))
+}
+
+func TestFollowerSupportsNamingSchemes(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	for name, data := range map[string]string{
+		"filename.20130916T115500.log": "a\n",
+		"filename.2.log":               "c\n",
+		"filename.10.log":              "d\n",
+		"filename.1.log":               "b\n",
+		filename:                       "e\n",
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), os.ModePerm)
+		assert.NoError(t, err)
+	}
+	f, err := NewFollower(filepath.Join(dir, filename), FollowOptions{
+		Naming: SwitchedNaming{NumericNaming{}, []NamingScheme{TimeNaming{}}},
+	})
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, readAll(t, f))
+}
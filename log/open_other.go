@@ -0,0 +1,22 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"os"
+)
+
+// openAppend opens name for appending, creating it if needed.
+func openAppend(name string) (*os.File, error) {
+	return os.OpenFile(name, os.O_CREATE+os.O_WRONLY+os.O_APPEND, os.ModePerm)
+}
@@ -0,0 +1,33 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// openAppend opens name for appending, creating it if needed. Unlike
+// os.OpenFile, the file is shared for deletion so that other processes can
+// rename or delete it while it is open, when rotating or pruning.
+func openAppend(name string) (*os.File, error) {
+	path, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	h, err := syscall.CreateFile(path,
+		syscall.FILE_APPEND_DATA|syscall.FILE_WRITE_ATTRIBUTES|syscall.SYNCHRONIZE,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(h), name), nil
+}
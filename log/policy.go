@@ -0,0 +1,60 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// now is overridden by tests to control rotation schedules.
+var now = time.Now
+
+// RotatePolicy returns the time at which a log file opened or last written
+// at t must be rotated.
+type RotatePolicy func(t time.Time) time.Time
+
+// Hourly rotates log files at the beginning of every hour.
+func Hourly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+}
+
+// Midnight rotates log files at local midnight.
+func Midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+}
+
+// Every rotates log files after the supplied interval.
+func Every(interval time.Duration) RotatePolicy {
+	return func(t time.Time) time.Time {
+		return t.Add(interval)
+	}
+}
+
+// ParseRotatePolicy converts a user supplied schedule into a RotatePolicy.
+// Valid values are "hourly", "daily" (every 24 hours), "midnight" or a
+// time.Duration string like "30m". An empty string returns a nil policy.
+func ParseRotatePolicy(value string) (RotatePolicy, error) {
+	switch value {
+	case "":
+		return nil, nil
+	case "hourly":
+		return Hourly, nil
+	case "daily":
+		return Every(24 * time.Hour), nil
+	case "midnight":
+		return Midnight, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid rotation policy: %q", value)
+	}
+	return Every(interval), nil
+}
@@ -0,0 +1,160 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setClock replaces the package clock and returns a function advancing it.
+func setClock(start time.Time) (func(time.Duration), func()) {
+	current := start
+	now = func() time.Time {
+		return current
+	}
+	advance := func(d time.Duration) {
+		current = current.Add(d)
+	}
+	return advance, func() {
+		now = time.Now
+	}
+}
+
+func TestRotatePolicies(t *testing.T) {
+	ref := time.Date(2016, 3, 14, 23, 30, 10, 0, time.Local)
+	assert.Equal(t, time.Date(2016, 3, 15, 0, 0, 0, 0, time.Local), Hourly(ref))
+	assert.Equal(t, time.Date(2016, 3, 15, 0, 0, 0, 0, time.Local), Midnight(ref))
+	assert.Equal(t, ref.Add(time.Minute), Every(time.Minute)(ref))
+}
+
+func TestParseRotatePolicy(t *testing.T) {
+	ref := time.Date(2016, 3, 14, 10, 30, 0, 0, time.Local)
+	for value, expected := range map[string]time.Time{
+		"hourly":   time.Date(2016, 3, 14, 11, 0, 0, 0, time.Local),
+		"daily":    time.Date(2016, 3, 15, 10, 30, 0, 0, time.Local),
+		"midnight": time.Date(2016, 3, 15, 0, 0, 0, 0, time.Local),
+		"90m":      time.Date(2016, 3, 14, 12, 0, 0, 0, time.Local),
+	} {
+		policy, err := ParseRotatePolicy(value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, policy(ref), value)
+	}
+	policy, err := ParseRotatePolicy("")
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+	for _, value := range []string{"weekly", "-1h", "0s"} {
+		_, err = ParseRotatePolicy(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRotatingLogRotatesAtMidnight(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 23, 59, 0, 0, time.Local))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 0, "bytes", true,
+		WithRotatePolicy(Midnight))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWrite(t, w)
+	advance(30 * time.Second)
+	checkWrite(t, w)
+	assert.Len(t, readFiles(t, dir), 1)
+	advance(time.Minute)
+	checkWrite(t, w)
+	files := readFiles(t, dir)
+	// The rotated file is named after the day it holds.
+	assert.Equal(t, []string{"filename.20160314T235900.log", filename}, files)
+	checkContent(t, filepath.Join(dir, files[0]), content+content)
+	checkContent(t, filepath.Join(dir, files[1]), content)
+}
+
+func TestRotatingLogNamesScheduledRotationsAfterTheirPeriod(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 0, "bytes", true,
+		WithRotatePolicy(Midnight), WithNaming(TimeNaming{Layout: "2006-01-02"}))
+	assert.NoError(t, err)
+	defer w.Close()
+	for i := 0; i < 3; i++ {
+		checkWrite(t, w)
+		advance(24 * time.Hour)
+	}
+	assert.Equal(t, []string{"filename.2016-03-14.log", "filename.2016-03-15.log", filename},
+		readFiles(t, dir))
+}
+
+func TestRotatingLogSkipsEmptyFilesOnSchedule(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 0, "bytes", true,
+		WithRotatePolicy(Hourly))
+	assert.NoError(t, err)
+	defer w.Close()
+	advance(2 * time.Hour)
+	checkWrite(t, w)
+	assert.Equal(t, []string{filename}, readFiles(t, dir))
+}
+
+func TestRotatingLogCombinesSizeAndTimeRotation(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, int64(2*len(content)), "bytes", true,
+		WithRotatePolicy(Every(time.Minute)))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWrite(t, w)
+	advance(time.Minute)
+	checkWrite(t, w)
+	advance(time.Second)
+	checkWrite(t, w)
+	checkWrite(t, w)
+	files := readFiles(t, dir)
+	assert.Equal(t, []string{
+		"filename.20160314T100000.log",
+		"filename.20160314T100101.log",
+		filename,
+	}, files)
+	checkContent(t, filepath.Join(dir, files[0]), content)
+	checkContent(t, filepath.Join(dir, files[1]), content+content)
+	checkContent(t, filepath.Join(dir, files[2]), content)
+}
+
+func TestRotatingLogRotatesStaleFileOnReopen(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	err := ioutil.WriteFile(log, []byte(content), os.ModePerm)
+	assert.NoError(t, err)
+	yesterday := time.Date(2016, 3, 13, 18, 0, 0, 0, time.Local)
+	err = os.Chtimes(log, yesterday, yesterday)
+	assert.NoError(t, err)
+	w, err := NewRotateWriter(log, -1, 0, "bytes", false, WithRotatePolicy(Midnight))
+	assert.NoError(t, err)
+	defer w.Close()
+	checkWrite(t, w)
+	files := readFiles(t, dir)
+	assert.Equal(t, []string{"filename.20160313T180000.log", filename}, files)
+	checkContent(t, filepath.Join(dir, files[1]), content)
+}
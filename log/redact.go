@@ -0,0 +1,186 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces sensitive values.
+const Redacted = "***"
+
+// redactValue matches a quoted or unquoted value, up to the end of a
+// formatted argument slice.
+const redactValue = `("(?:[^"\\]|\\.)*"|[^\s\]"]+)`
+
+type redactPattern struct {
+	re *regexp.Regexp
+	// group is the masked sub-match, 0 for the whole match.
+	group int
+}
+
+// Redactor masks sensitive values: header values, flag values, key/value
+// fields and arbitrary patterns. It is safe for concurrent use.
+type Redactor struct {
+	mutex    sync.RWMutex
+	patterns []redactPattern
+	flags    map[string]bool
+}
+
+// NewRedactor creates a Redactor without sensitive patterns.
+func NewRedactor() *Redactor {
+	return &Redactor{flags: map[string]bool{}}
+}
+
+func (r *Redactor) add(re *regexp.Regexp, group int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.patterns = append(r.patterns, redactPattern{re, group})
+}
+
+// AddHeader masks the values of the supplied HTTP header, case insensitively,
+// in "Name: value" lines.
+func (r *Redactor) AddHeader(name string) {
+	r.add(regexp.MustCompile(`(?im)^[ \t]*`+regexp.QuoteMeta(name)+`[ \t]*:[ \t]*([^\r\n]+)`), 1)
+}
+
+// AddFlag masks the values of the supplied command line flag, like
+// "-name value" or "--name=value", and of "name=value" or JSON "name"
+// fields.
+func (r *Redactor) AddFlag(name string) {
+	r.mutex.Lock()
+	r.flags[name] = true
+	r.mutex.Unlock()
+	quoted := regexp.QuoteMeta(name)
+	r.add(regexp.MustCompile(`(?:^|[\s\[])--?`+quoted+`(?:=|\s+)`+redactValue), 1)
+	r.add(regexp.MustCompile(`(?:^|\s)`+quoted+`=`+redactValue), 1)
+	r.add(regexp.MustCompile(`"`+quoted+`"\s*:\s*("(?:[^"\\]|\\.)*")`), 1)
+}
+
+// AddPattern masks the matches of re, or of its first sub-expression if
+// any.
+func (r *Redactor) AddPattern(re *regexp.Regexp) {
+	group := 0
+	if re.NumSubexp() > 0 {
+		group = 1
+	}
+	r.add(re, group)
+}
+
+// Redact returns p with sensitive values masked. p is returned unchanged if
+// nothing had to be masked.
+func (r *Redactor) Redact(p []byte) []byte {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, pattern := range r.patterns {
+		matches := pattern.re.FindAllSubmatchIndex(p, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		buf := &bytes.Buffer{}
+		last := 0
+		for _, m := range matches {
+			start, end := m[2*pattern.group], m[2*pattern.group+1]
+			if start < 0 || start < last {
+				continue
+			}
+			buf.Write(p[last:start])
+			buf.WriteString(Redacted)
+			last = end
+		}
+		buf.Write(p[last:])
+		p = buf.Bytes()
+	}
+	return p
+}
+
+// RedactArgs returns a copy of command line arguments with the values of
+// sensitive flags masked.
+func (r *Redactor) RedactArgs(args []string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	redacted := make([]string, len(args))
+	masked := false
+	for i, arg := range args {
+		if masked {
+			redacted[i] = Redacted
+			masked = false
+			continue
+		}
+		redacted[i] = arg
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if j := strings.Index(name, "="); j >= 0 {
+			if r.flags[name[:j]] {
+				redacted[i] = arg[:len(arg)-len(name)+j+1] + Redacted
+			}
+			continue
+		}
+		masked = r.flags[name]
+	}
+	return redacted
+}
+
+// RedactingWriter masks sensitive values before forwarding entries to
+// another writer.
+type RedactingWriter struct {
+	w io.Writer
+	r *Redactor
+}
+
+// NewRedactingWriter creates a RedactingWriter forwarding to w. A nil
+// redactor uses the sensitive patterns registered at package level.
+func NewRedactingWriter(w io.Writer, r *Redactor) *RedactingWriter {
+	if r == nil {
+		r = sensitive
+	}
+	return &RedactingWriter{w: w, r: r}
+}
+
+func (w *RedactingWriter) Write(p []byte) (int, error) {
+	_, err := w.w.Write(w.r.Redact(p))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+var sensitive = newSensitive()
+
+func newSensitive() *Redactor {
+	r := NewRedactor()
+	r.AddHeader("MASA-SID")
+	r.AddHeader("Authorization")
+	r.AddFlag("password")
+	return r
+}
+
+// Sensitive returns the registry of sensitive patterns used by default,
+// which masks "MASA-SID" and "Authorization" headers and "password" flags.
+func Sensitive() *Redactor {
+	return sensitive
+}
+
+// Redact masks the sensitive values registered at package level.
+func Redact(p []byte) []byte {
+	return sensitive.Redact(p)
+}
+
+// RedactArgs masks the values of the sensitive flags registered at package
+// level.
+func RedactArgs(args []string) []string {
+	return sensitive.RedactArgs(args)
+}
@@ -0,0 +1,68 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestRedactorMasksHeaders(t *testing.T) {
+	r := NewRedactor()
+	r.AddHeader("MASA-SID")
+	assert.Equal(t, "Content-Type: application/json\nMasa-Sid: ***\n",
+		string(r.Redact([]byte("Content-Type: application/json\nMasa-Sid: 1234\n"))))
+	assert.Equal(t, "masa-sid:***\r\n", string(r.Redact([]byte("masa-sid:1234\r\n"))))
+	assert.Equal(t, "X-Masa-Sid: 1234\n", string(r.Redact([]byte("X-Masa-Sid: 1234\n"))))
+}
+
+func TestRedactorMasksFlags(t *testing.T) {
+	r := NewRedactor()
+	r.AddFlag("password")
+	for input, expected := range map[string]string{
+		"command line [app -password secret -debug]": "command line [app -password *** -debug]",
+		"command line [app --password=secret]":       "command line [app --password=***]",
+		"command line [app -password-file f]":        "command line [app -password-file f]",
+		`INFO login user=bob password="a b" ok=true`: `INFO login user=bob password=*** ok=true`,
+		"INFO login user=bob password=secret":        "INFO login user=bob password=***",
+		"INFO login user=bob old-password=secret":    "INFO login user=bob old-password=secret",
+		`{"user": "bob", "password": "a \"b\""}`:     `{"user": "bob", "password": ***}`,
+	} {
+		assert.Equal(t, expected, string(r.Redact([]byte(input))), input)
+	}
+	assert.Equal(t,
+		[]string{"app", "-password", Redacted, "--password=" + Redacted, "-debug", "password"},
+		r.RedactArgs([]string{"app", "-password", "secret", "--password=secret", "-debug", "password"}))
+}
+
+func TestRedactorMasksPatterns(t *testing.T) {
+	r := NewRedactor()
+	r.AddPattern(regexp.MustCompile(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`))
+	r.AddPattern(regexp.MustCompile(`token=(\w+)`))
+	assert.Equal(t, "card *** token=*** end",
+		string(r.Redact([]byte("card 1234-5678-9012-3456 token=abc end"))))
+	input := []byte("nothing to hide")
+	assert.Equal(t, input, r.Redact(input))
+}
+
+func TestRedactingWriterUsesSensitivePatterns(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewRedactingWriter(buf, nil)
+	n, err := fmt.Fprintln(w, "command line", []string{"app", "-password", "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, len("command line [app -password secret]\n"), n)
+	assert.Equal(t, "command line [app -password ***]\n", buf.String())
+	buf.Reset()
+	w.Write([]byte("MASA-SID: 1234\n"))
+	assert.Equal(t, "MASA-SID: ***\n", buf.String())
+}
@@ -0,0 +1,158 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// RingWriter is an io.Writer keeping the last log entries in memory, each
+// Write call being an entry. It is safe for concurrent use and serves its
+// entries over HTTP.
+type RingWriter struct {
+	mutex   sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+	// subscribers receive new entries, those too slow to keep up miss
+	// some of them.
+	subscribers map[chan []byte]struct{}
+}
+
+// NewRingWriter creates a RingWriter keeping the last size entries.
+func NewRingWriter(size int) *RingWriter {
+	if size < 1 {
+		size = 1
+	}
+	return &RingWriter{
+		entries:     make([][]byte, size),
+		subscribers: map[chan []byte]struct{}{},
+	}
+}
+
+func (r *RingWriter) Write(p []byte) (int, error) {
+	entry := append([]byte{}, p...)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	for s := range r.subscribers {
+		select {
+		case s <- entry:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Entries returns the kept entries, oldest first.
+func (r *RingWriter) Entries() [][]byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.snapshot()
+}
+
+func (r *RingWriter) snapshot() [][]byte {
+	entries := make([][]byte, 0, len(r.entries))
+	if r.full {
+		entries = append(entries, r.entries[r.next:]...)
+	}
+	return append(entries, r.entries[:r.next]...)
+}
+
+// subscribe returns the kept entries and a channel receiving the next ones.
+func (r *RingWriter) subscribe() ([][]byte, chan []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := make(chan []byte, 256)
+	r.subscribers[s] = struct{}{}
+	return r.snapshot(), s
+}
+
+func (r *RingWriter) unsubscribe(s chan []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.subscribers, s)
+}
+
+// ServeHTTP writes the kept entries as plain text. The optional "filter"
+// parameter is a regular expression entries must match, "n" limits the
+// output to the last n matching entries and "follow" keeps streaming new
+// entries until the client disconnects.
+func (r *RingWriter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	filter, err := regexp.Compile(query.Get("filter"))
+	if err != nil {
+		http.Error(w, "invalid filter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := -1
+	if n := query.Get("n"); n != "" {
+		limit, err = strconv.Atoi(n)
+		if err != nil || limit < 0 {
+			http.Error(w, "invalid n: "+n, http.StatusBadRequest)
+			return
+		}
+	}
+	follow := query.Get("follow") != "" && query.Get("follow") != "0" &&
+		query.Get("follow") != "false"
+	var entries [][]byte
+	var s chan []byte
+	if follow {
+		entries, s = r.subscribe()
+		defer r.unsubscribe(s)
+	} else {
+		entries = r.Entries()
+	}
+	matching := entries[:0]
+	for _, entry := range entries {
+		if filter.Match(entry) {
+			matching = append(matching, entry)
+		}
+	}
+	if limit >= 0 && len(matching) > limit {
+		matching = matching[len(matching)-limit:]
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	for _, entry := range matching {
+		_, err = w.Write(entry)
+		if err != nil {
+			return
+		}
+	}
+	if !follow {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	for {
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case <-req.Context().Done():
+			return
+		case entry := <-s:
+			if !filter.Match(entry) {
+				continue
+			}
+			_, err = w.Write(entry)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
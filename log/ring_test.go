@@ -0,0 +1,73 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestRingWriterKeepsLastEntries(t *testing.T) {
+	r := NewRingWriter(3)
+	assert.Empty(t, r.Entries())
+	for i := 0; i < 5; i++ {
+		n, err := r.Write([]byte("entry " + strconv.Itoa(i) + "\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 8, n)
+	}
+	assert.Equal(t, [][]byte{
+		[]byte("entry 2\n"),
+		[]byte("entry 3\n"),
+		[]byte("entry 4\n"),
+	}, r.Entries())
+}
+
+func serveRing(r *RingWriter, query string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?"+query, nil))
+	return rec
+}
+
+func TestRingWriterServesFilteredEntries(t *testing.T) {
+	r := NewRingWriter(10)
+	for _, entry := range []string{"INFO a\n", "ERROR b\n", "INFO c\n", "ERROR d\n", "ERROR e\n"} {
+		r.Write([]byte(entry))
+	}
+	rec := serveRing(r, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "INFO a\nERROR b\nINFO c\nERROR d\nERROR e\n", rec.Body.String())
+	assert.Equal(t, "ERROR b\nERROR d\nERROR e\n", serveRing(r, "filter=ERROR").Body.String())
+	assert.Equal(t, "ERROR d\nERROR e\n", serveRing(r, "filter=ERROR&n=2").Body.String())
+	assert.Equal(t, http.StatusBadRequest, serveRing(r, "filter=(").Code)
+	assert.Equal(t, http.StatusBadRequest, serveRing(r, "n=-1").Code)
+}
+
+func TestRingWriterStreamsEntries(t *testing.T) {
+	r := NewRingWriter(10)
+	r.Write([]byte("INFO a\n"))
+	server := httptest.NewServer(r)
+	defer server.Close()
+	rsp, err := http.Get(server.URL + "?follow=1&filter=INFO")
+	assert.NoError(t, err)
+	defer rsp.Body.Close()
+	reader := bufio.NewReader(rsp.Body)
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "INFO a\n", line)
+	r.Write([]byte("ERROR b\n"))
+	r.Write([]byte("INFO c\n"))
+	line, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "INFO c\n", line)
+}
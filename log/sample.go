@@ -0,0 +1,215 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type sampled struct {
+	// count is the number of entries seen during the current period and
+	// suppressed the number of entries dropped since the last summary.
+	count      int
+	suppressed int
+	example    []byte
+}
+
+// SamplingWriter limits the rate of log entries: during each period, the
+// first burst entries are written, then only one in every. Entries are
+// limited globally or per pattern, and the number of suppressed entries is
+// written periodically. It is safe for concurrent use.
+type SamplingWriter struct {
+	// suppressed counts all dropped entries, it is updated atomically and
+	// must stay first to be 64-bit aligned on 32-bit platforms.
+	suppressed int64
+	mutex      sync.Mutex
+	w          io.Writer
+	burst      int
+	every      int
+	period     time.Duration
+	start      time.Time
+	pattern    *regexp.Regexp
+	perKey     bool
+	counters   map[string]*sampled
+	prefix     string
+	interval   time.Duration
+	timer      *time.Timer
+}
+
+// SampleOption configures optional SamplingWriter behaviours.
+type SampleOption func(*SamplingWriter)
+
+// WithSamplePeriod sets the period over which entries are counted, it
+// defaults to one second.
+func WithSamplePeriod(period time.Duration) SampleOption {
+	return func(w *SamplingWriter) {
+		w.period = period
+	}
+}
+
+// WithSamplePatterns limits entries per pattern instead of globally,
+// entries only differing by parts matching the normalizers sharing the same
+// limit. Without normalizers, identical entries share a limit.
+func WithSamplePatterns(normalizers ...*regexp.Regexp) SampleOption {
+	return func(w *SamplingWriter) {
+		w.perKey = true
+		if len(normalizers) > 0 {
+			w.pattern = regexp.MustCompile(alternatives(normalizers))
+		}
+	}
+}
+
+// WithSummaryInterval writes the number of suppressed entries at most
+// interval after the first of them, it defaults to 10 seconds.
+func WithSummaryInterval(interval time.Duration) SampleOption {
+	return func(w *SamplingWriter) {
+		w.interval = interval
+	}
+}
+
+// WithSummaryPrefix starts summaries with prefix, like the "<app> " prefix
+// of the standard log package.
+func WithSummaryPrefix(prefix string) SampleOption {
+	return func(w *SamplingWriter) {
+		w.prefix = prefix
+	}
+}
+
+// NewSamplingWriter creates a SamplingWriter writing the first burst entries
+// of every period to w, then one in every entries. every values below 1
+// suppress all entries exceeding the burst.
+func NewSamplingWriter(w io.Writer, burst, every int, options ...SampleOption) *SamplingWriter {
+	s := &SamplingWriter{
+		w:        w,
+		burst:    burst,
+		every:    every,
+		period:   time.Second,
+		counters: map[string]*sampled{},
+		interval: 10 * time.Second,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+func (w *SamplingWriter) key(p []byte) string {
+	if !w.perKey {
+		return ""
+	}
+	if w.pattern != nil {
+		return string(w.pattern.ReplaceAll(p, []byte{0}))
+	}
+	return string(p)
+}
+
+func (w *SamplingWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	t := now()
+	if t.Sub(w.start) >= w.period {
+		w.start = t
+		for key, c := range w.counters {
+			if c.suppressed == 0 {
+				delete(w.counters, key)
+			} else {
+				c.count = 0
+			}
+		}
+	}
+	key := w.key(p)
+	c := w.counters[key]
+	if c == nil {
+		c = &sampled{}
+		w.counters[key] = c
+	}
+	c.count++
+	extra := c.count - w.burst
+	if extra <= 0 || w.every > 0 && extra%w.every == 0 {
+		_, err := w.w.Write(p)
+		if err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if c.suppressed == 0 {
+		c.example = append([]byte(nil), p...)
+	}
+	c.suppressed++
+	atomic.AddInt64(&w.suppressed, 1)
+	if w.interval > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.interval, w.onTimer)
+	}
+	return len(p), nil
+}
+
+// Suppressed returns the number of entries dropped since the writer
+// creation.
+func (w *SamplingWriter) Suppressed() int64 {
+	return atomic.LoadInt64(&w.suppressed)
+}
+
+func (w *SamplingWriter) onTimer() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timer != nil {
+		w.timer = nil
+		w.summarize()
+	}
+}
+
+// summarize writes the suppressed entries counts, per pattern in the order
+// of their examples.
+func (w *SamplingWriter) summarize() {
+	counters := []*sampled{}
+	for _, c := range w.counters {
+		if c.suppressed > 0 {
+			counters = append(counters, c)
+		}
+	}
+	sort.Slice(counters, func(i, j int) bool {
+		return string(counters[i].example) < string(counters[j].example)
+	})
+	for _, c := range counters {
+		if w.perKey {
+			fmt.Fprintf(w.w, "%ssuppressed %d log entries like: %s\n", w.prefix, c.suppressed,
+				strings.TrimRight(string(c.example), "\n"))
+		} else {
+			fmt.Fprintf(w.w, "%ssuppressed %d log entries\n", w.prefix, c.suppressed)
+		}
+		c.suppressed = 0
+		c.example = nil
+	}
+}
+
+// Flush writes pending suppressed entries counts.
+func (w *SamplingWriter) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.summarize()
+	return nil
+}
+
+// Close flushes pending counts and stops the summary timer. It does not
+// close the underlying writer.
+func (w *SamplingWriter) Close() error {
+	return w.Flush()
+}
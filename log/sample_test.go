@@ -0,0 +1,68 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSamplingWriterLimitsGlobalRate(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	buf := &bytes.Buffer{}
+	w := NewSamplingWriter(buf, 2, 3, WithSummaryInterval(0), WithSummaryPrefix("<test> "))
+	for i := 1; i <= 9; i++ {
+		n, err := w.Write([]byte("entry " + strconv.Itoa(i) + "\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 8, n)
+	}
+	assert.Equal(t, "entry 1\nentry 2\nentry 5\nentry 8\n", buf.String())
+	assert.EqualValues(t, 5, w.Suppressed())
+	// A new period starts with a new burst.
+	advance(time.Second)
+	buf.Reset()
+	w.Write([]byte("entry 10\n"))
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "entry 10\n<test> suppressed 5 log entries\n", buf.String())
+	buf.Reset()
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "", buf.String())
+}
+
+func TestSamplingWriterLimitsRatePerPattern(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	buf := &bytes.Buffer{}
+	w := NewSamplingWriter(buf, 1, 0, WithSamplePatterns(Numbers), WithSummaryInterval(0))
+	for i := 0; i < 3; i++ {
+		w.Write([]byte("unit " + strconv.Itoa(i) + " lost contact\n"))
+		w.Write([]byte("tick " + strconv.Itoa(i) + " failed\n"))
+	}
+	assert.Equal(t, "unit 0 lost contact\ntick 0 failed\n", buf.String())
+	buf.Reset()
+	w.Flush()
+	assert.Equal(t, "suppressed 2 log entries like: tick 1 failed\n"+
+		"suppressed 2 log entries like: unit 1 lost contact\n", buf.String())
+}
+
+func TestSamplingWriterWritesSummariesPeriodically(t *testing.T) {
+	buf := &lockedBuffer{}
+	w := NewSamplingWriter(buf, 0, 0, WithSummaryInterval(10*time.Millisecond))
+	defer w.Close()
+	w.Write([]byte("entry\n"))
+	w.Write([]byte("entry\n"))
+	assert.Eventually(t, func() bool {
+		return buf.String() == "suppressed 2 log entries\n"
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,112 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// SlogHandler is a slog.Handler writing records through a Logger, so that
+// they share its level, prefix and writers: a TimeWriter or a JSONWriter
+// behind a CollapsingWriter and a RotateWriter. Records are stamped by these
+// writers, their own time is ignored. Groups are flattened into dotted keys.
+type SlogHandler struct {
+	logger *Logger
+	group  string
+	// trim is removed from the start of messages.
+	trim string
+}
+
+// NewSlogHandler returns a handler writing to l. A nil logger uses the
+// default logger level with the current standard log package output and
+// prefix, as configured by util.Parse, so that the handler can be passed to
+// slog.SetDefault. The default logger is then bound to this output too, so
+// this must not be done while it is in use.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	if l == nil {
+		// slog.SetDefault redirects the standard log package output to the
+		// handler, the handler and the default logger must keep writing to
+		// the original one.
+		if std.w == nil {
+			std.w, std.prefix = log.Writer(), log.Prefix()
+		}
+		l = Default().With()
+		// Entries redirected from the standard log package keep its prefix.
+		return &SlogHandler{logger: l, trim: l.prefix}
+	}
+	return &SlogHandler{logger: l}
+}
+
+// fromSlog maps slog levels to the closest Level below them.
+func fromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	}
+	return LevelError
+}
+
+// appendAttr appends the key/value pairs of a to keyvals, prefixing keys
+// with group.
+func appendAttr(keyvals []interface{}, group string, a slog.Attr) []interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return keyvals
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, attr := range a.Value.Group() {
+			keyvals = appendAttr(keyvals, group, attr)
+		}
+		return keyvals
+	}
+	return append(keyvals, group+a.Key, a.Value.Any())
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.Enabled(fromSlog(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	keyvals := make([]interface{}, 0, 2*r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		keyvals = appendAttr(keyvals, h.group, a)
+		return true
+	})
+	h.logger.Log(fromSlog(r.Level), strings.TrimPrefix(r.Message, h.trim), keyvals...)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	keyvals := []interface{}{}
+	for _, a := range attrs {
+		keyvals = appendAttr(keyvals, h.group, a)
+	}
+	return &SlogHandler{logger: h.logger.With(keyvals...), group: h.group, trim: h.trim}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, group: h.group + name + ".", trim: h.trim}
+}
@@ -0,0 +1,84 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandlerFormatsRecords(t *testing.T) {
+	b := bytes.Buffer{}
+	l := slog.New(NewSlogHandler(NewLogger(&b, "<test> ", LevelInfo)))
+	l.Debug("hidden")
+	l.Info("started", "log", "some file.log", "max-age", time.Hour)
+	assert.Equal(t, "<test> INFO started log=\"some file.log\" max-age=1h0m0s\n", b.String())
+	b.Reset()
+	l.With("unit", 12).WithGroup("req").With("id", 3).Error("failed",
+		slog.Group("http", "status", 500), slog.Attr{}, slog.Group("", "flat", true))
+	assert.Equal(t, "<test> ERROR failed unit=12 req.id=3 req.http.status=500 req.flat=true\n", b.String())
+	b.Reset()
+	l.Log(nil, slog.LevelWarn+1, "odd")
+	assert.Equal(t, "<test> WARN odd\n", b.String())
+}
+
+func TestSlogHandlerWritesThroughMASAWriters(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.UTC))
+	defer reset()
+	b := bytes.Buffer{}
+	l := slog.New(NewSlogHandler(NewLogger(MakeJSONCollapsingWriter(&b), "<test> ", LevelInfo)))
+	l.Info("lost contact", "unit", 12)
+	l.Info("lost contact", "unit", 12)
+	e := Entry{}
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &e))
+	assert.Equal(t, Entry{
+		Time:    now(),
+		Prefix:  "test",
+		Level:   "INFO",
+		Message: "lost contact",
+		Fields:  Fields{{"unit", "12"}},
+	}, e)
+}
+
+func TestSlogHandlerCanBeTheDefault(t *testing.T) {
+	b := bytes.Buffer{}
+	writer, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	defaultLogger := slog.Default()
+	defer func() {
+		std.w, std.prefix = nil, ""
+		SetLevel(LevelInfo)
+		slog.SetDefault(defaultLogger)
+		log.SetOutput(writer)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}()
+	log.SetOutput(&b)
+	log.SetPrefix("<test> ")
+	log.SetFlags(0)
+	slog.SetDefault(slog.New(NewSlogHandler(nil)))
+	slog.Info("started", "unit", 12)
+	log.Println("legacy")
+	Error("boom", "unit", 12)
+	assert.Equal(t, "<test> INFO started unit=12\n<test> INFO legacy\n"+
+		"<test> ERROR boom unit=12\n", b.String())
+	b.Reset()
+	SetLevel(LevelWarn)
+	Error("boom")
+	Info("hidden")
+	assert.Equal(t, "<test> ERROR boom\n", b.String())
+}
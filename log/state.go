@@ -0,0 +1,135 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+var (
+	// maxLineScan bounds the number of bytes read to count lines when
+	// re-opening a log file.
+	maxLineScan int64 = 4 * 1048576
+	// stateInterval is the number of bytes written between two saves of the
+	// line count state, it must be lower than maxLineScan so that the line
+	// count remains exact after a crash.
+	stateInterval int64 = 1048576
+)
+
+// lineState records the number of lines of a log file when it had the given
+// size and modification time.
+type lineState struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modtime"`
+	Lines   int64     `json:"lines"`
+}
+
+func stateFile(filename string) string {
+	return filename + ".state"
+}
+
+func readState(filename string) (*lineState, error) {
+	data, err := ioutil.ReadFile(stateFile(filename))
+	if err != nil {
+		return nil, err
+	}
+	state := &lineState{}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func writeState(filename string, state *lineState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(stateFile(filename), data, os.ModePerm)
+}
+
+// countLinesFrom counts the lines of the file between offset and its end.
+func countLinesFrom(file *os.File, offset int64) (int64, error) {
+	_, err := file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	lines := int64(0)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := file.Read(buf)
+		lines += lineCount(buf[:n])
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// countLines returns the number of lines of filename. It relies on the
+// state saved along the file when it matches the file size and modification
+// time, counts lines appended since the last saved state, or counts all lines
+// of small files. Large files without a usable state get their line count
+// extrapolated from their last maxLineScan bytes.
+func countLines(filename string, info os.FileInfo) (int64, error) {
+	size := info.Size()
+	state, _ := readState(filename)
+	if state != nil && state.Size == size && state.ModTime.Equal(info.ModTime()) {
+		return state.Lines, nil
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	if state != nil && state.Size <= size && size-state.Size <= maxLineScan {
+		lines, err := countLinesFrom(file, state.Size)
+		if err != nil {
+			return 0, err
+		}
+		return state.Lines + lines, nil
+	}
+	if size <= maxLineScan {
+		return countLinesFrom(file, 0)
+	}
+	tail := make([]byte, maxLineScan)
+	_, err = file.ReadAt(tail, size-maxLineScan)
+	if err != nil {
+		return 0, err
+	}
+	lines := bytes.Count(tail, []byte{'\n'})
+	if lines == 0 {
+		// Use the hard-coded line size historically applied to all files.
+		return size / 200, nil
+	}
+	return size * int64(lines) / maxLineScan, nil
+}
+
+// saveState records the current line count along the log file.
+func (w *RotateWriter) saveState() error {
+	info, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	w.saved = w.offset
+	return writeState(w.filename, &lineState{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Lines:   w.size,
+	})
+}
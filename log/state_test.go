@@ -0,0 +1,148 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	shortline = "x\n"
+)
+
+func appendLines(t *testing.T, filename string, count int) {
+	file, err := os.OpenFile(filename, os.O_WRONLY+os.O_APPEND, os.ModePerm)
+	assert.NoError(t, err)
+	defer file.Close()
+	_, err = file.WriteString(strings.Repeat(shortline, count))
+	assert.NoError(t, err)
+}
+
+// checkRotatesAfter writes lines until the writer rotates and checks it
+// happens after exactly count lines.
+func checkRotatesAfter(t *testing.T, w *RotateWriter, dir string, count int) {
+	for i := 0; i < count; i++ {
+		_, err := w.Write([]byte(shortline))
+		assert.NoError(t, err)
+		assert.NotContains(t, strings.Join(readFiles(t, dir), " "), "filename.2")
+	}
+	_, err := w.Write([]byte(shortline))
+	assert.NoError(t, err)
+	assert.Contains(t, strings.Join(readFiles(t, dir), " "), "filename.2")
+}
+
+func TestRotatingLogRestoresLineCountFromState(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	w, err := NewRotateWriter(log, -1, 10, "lines", true)
+	assert.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		checkWriteLine(t, w)
+	}
+	assert.NoError(t, w.Close())
+	assert.Equal(t, []string{filename, filename + ".state"}, readFiles(t, dir))
+	w, err = NewRotateWriter(log, -1, 10, "lines", false)
+	assert.NoError(t, err)
+	defer w.Close()
+	checkRotatesAfter(t, w, dir, 4)
+}
+
+func TestRotatingLogCountsLinesAppendedAfterState(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	w, err := NewRotateWriter(log, -1, 10, "lines", true)
+	assert.NoError(t, err)
+	checkWriteLine(t, w)
+	checkWriteLine(t, w)
+	assert.NoError(t, w.Close())
+	// Simulate a crash after lines were written past the saved state.
+	appendLines(t, log, 5)
+	w, err = NewRotateWriter(log, -1, 10, "lines", false)
+	assert.NoError(t, err)
+	defer w.Close()
+	checkRotatesAfter(t, w, dir, 3)
+}
+
+func TestRotatingLogCountsLinesWithoutState(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	err := ioutil.WriteFile(log, []byte(strings.Repeat(shortline, 7)), os.ModePerm)
+	assert.NoError(t, err)
+	w, err := NewRotateWriter(log, -1, 10, "lines", false)
+	assert.NoError(t, err)
+	defer w.Close()
+	checkRotatesAfter(t, w, dir, 3)
+}
+
+func TestRotatingLogIgnoresStaleState(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	w, err := NewRotateWriter(log, -1, 10, "lines", true)
+	assert.NoError(t, err)
+	for i := 0; i < 8; i++ {
+		checkWriteLine(t, w)
+	}
+	assert.NoError(t, w.Close())
+	// The file got replaced by a shorter one.
+	err = ioutil.WriteFile(log, []byte(strings.Repeat(shortline, 2)), os.ModePerm)
+	assert.NoError(t, err)
+	w, err = NewRotateWriter(log, -1, 10, "lines", false)
+	assert.NoError(t, err)
+	defer w.Close()
+	checkRotatesAfter(t, w, dir, 8)
+}
+
+func TestRotatingLogSavesStatePeriodically(t *testing.T) {
+	defer func(interval int64) {
+		stateInterval = interval
+	}(stateInterval)
+	stateInterval = int64(3 * len(shortline))
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	w, err := NewRotateWriter(log, -1, 10, "lines", true)
+	assert.NoError(t, err)
+	defer w.Close()
+	for i := 0; i < 4; i++ {
+		_, err := w.Write([]byte(shortline))
+		assert.NoError(t, err)
+	}
+	state, err := readState(log)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), state.Lines)
+	assert.Equal(t, int64(3*len(shortline)), state.Size)
+}
+
+func TestCountLinesExtrapolatesLargeFiles(t *testing.T) {
+	defer func(scan int64) {
+		maxLineScan = scan
+	}(maxLineScan)
+	maxLineScan = int64(10 * len(shortline))
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, filename)
+	err := ioutil.WriteFile(log, []byte(strings.Repeat(someline, 10)+strings.Repeat(shortline, 40)), os.ModePerm)
+	assert.NoError(t, err)
+	info, err := os.Stat(log)
+	assert.NoError(t, err)
+	lines, err := countLines(log, info)
+	assert.NoError(t, err)
+	// The last bytes hold short lines only.
+	assert.Equal(t, info.Size()/int64(len(shortline)), lines)
+}
@@ -0,0 +1,317 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsOptions configures AnalyzeLog.
+type StatsOptions struct {
+	// Naming names rotated log files, it defaults to TimeNaming.
+	Naming NamingScheme
+	// Top is the number of most frequent messages reported, it defaults
+	// to 10.
+	Top int
+	// Gap is the minimum delay between consecutive entries reported as a
+	// gap, it defaults to one minute.
+	Gap time.Duration
+	// Normalizers mask the variable parts of messages counted together,
+	// like the CollapsingWriter ones.
+	Normalizers []*regexp.Regexp
+}
+
+// MessageCount is the number of occurrences of a message, including
+// collapsed ones. Variable parts of the message are replaced with "#".
+type MessageCount struct {
+	Message string
+	Count   int
+}
+
+// HourCount is the number of errors and warnings logged during an hour.
+type HourCount struct {
+	Hour     time.Time
+	Errors   int
+	Warnings int
+}
+
+// Gap is a period without log entries.
+type Gap struct {
+	From time.Time
+	To   time.Time
+}
+
+// LogStats summarizes a log file and its rotated files.
+type LogStats struct {
+	Files []string
+	// Lines counts all lines, Entries the timestamped ones.
+	Lines   int
+	Entries int
+	First   time.Time
+	Last    time.Time
+	Top     []MessageCount
+	// Collapsed counts the " ...xN" summaries written by CollapsingWriter
+	// and Repeated the entries they stand for.
+	Collapsed int
+	Repeated  int
+	// PerHour lists the hours with errors or warnings, in order.
+	PerHour []HourCount
+	Gaps    []Gap
+	// Skipped lists the errors of rotated files which could not be read.
+	Skipped []string
+}
+
+// collapsedCount matches CollapsingWriter summaries, with an optional
+// message.
+var collapsedCount = regexp.MustCompile(`^(.*?) ?\.\.\.x(\d+)$`)
+
+type statsCollector struct {
+	stats   *LogStats
+	options StatsOptions
+	pattern *regexp.Regexp
+	counts  map[string]int
+	hours   map[time.Time]*HourCount
+	// previous and previousLevel describe the last entry, which summaries
+	// without message refer to.
+	previous      string
+	previousLevel string
+}
+
+func newStatsCollector(options StatsOptions) *statsCollector {
+	if options.Top <= 0 {
+		options.Top = 10
+	}
+	if options.Gap <= 0 {
+		options.Gap = time.Minute
+	}
+	c := &statsCollector{
+		stats:   &LogStats{},
+		options: options,
+		counts:  map[string]int{},
+		hours:   map[time.Time]*HourCount{},
+	}
+	if len(options.Normalizers) > 0 {
+		// Collapsed entries report variable parts as "first..last".
+		p := alternatives(options.Normalizers)
+		c.pattern = regexp.MustCompile("(?:" + p + `)(?:\.\.(?:` + p + "))?")
+	}
+	return c
+}
+
+func (c *statsCollector) key(e Entry) string {
+	key := strings.TrimSpace(e.Message)
+	if e.Level != "" {
+		key = e.Level + " " + key
+	}
+	if e.Prefix != "" {
+		key = "<" + e.Prefix + "> " + key
+	}
+	if c.pattern != nil {
+		key = c.pattern.ReplaceAllString(key, "#")
+	}
+	return key
+}
+
+// level returns the entry level, guessed from the message of entries
+// written with the standard log package.
+func level(e Entry) string {
+	if e.Level != "" {
+		return e.Level
+	}
+	message := strings.ToLower(e.Message)
+	switch {
+	case strings.Contains(message, "panic") || strings.Contains(message, "error") ||
+		strings.Contains(message, "fail"):
+		return "ERROR"
+	case strings.Contains(message, "warn"):
+		return "WARN"
+	}
+	return ""
+}
+
+// parseLine returns the entry of a TimeWriter or JSONWriter line and the
+// count of a collapsed summary, zero for regular entries.
+func parseLine(line string) (Entry, int, bool) {
+	if strings.HasPrefix(line, "{") {
+		e := Entry{}
+		err := json.Unmarshal([]byte(line), &e)
+		if err != nil || e.Time.IsZero() {
+			return e, 0, false
+		}
+		return e, e.Repeat, true
+	}
+	t, ok := parseTimestamp(line)
+	if !ok {
+		return Entry{}, 0, false
+	}
+	rest := ""
+	if i := strings.Index(line, "] "); i >= 0 {
+		rest = line[i+2:]
+	}
+	count := 0
+	if m := collapsedCount.FindStringSubmatch(rest); m != nil {
+		count, _ = strconv.Atoi(m[2])
+		rest = m[1]
+	}
+	e := ParseEntry(rest)
+	e.Time = t
+	return e, count, true
+}
+
+func (c *statsCollector) add(line string) {
+	c.stats.Lines++
+	e, count, ok := parseLine(line)
+	if !ok {
+		// Continuation line.
+		return
+	}
+	c.stats.Entries++
+	if !c.stats.Last.IsZero() && e.Time.Sub(c.stats.Last) >= c.options.Gap {
+		c.stats.Gaps = append(c.stats.Gaps, Gap{From: c.stats.Last, To: e.Time})
+	}
+	if c.stats.First.IsZero() {
+		c.stats.First = e.Time
+	}
+	c.stats.Last = e.Time
+	occurrences := 1
+	key := c.key(e)
+	l := level(e)
+	if count > 0 {
+		c.stats.Collapsed++
+		// The first occurrence was logged on its own.
+		occurrences = count - 1
+		c.stats.Repeated += occurrences
+		if strings.TrimSpace(e.Message) == "" {
+			key = c.previous
+			l = c.previousLevel
+		}
+	}
+	c.counts[key] += occurrences
+	c.previous = key
+	c.previousLevel = l
+	if l != "ERROR" && l != "WARN" {
+		return
+	}
+	t := e.Time.Local()
+	hour := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.Local)
+	h := c.hours[hour]
+	if h == nil {
+		h = &HourCount{Hour: hour}
+		c.hours[hour] = h
+	}
+	if l == "ERROR" {
+		h.Errors += occurrences
+	} else {
+		h.Warnings += occurrences
+	}
+}
+
+func (c *statsCollector) finish() *LogStats {
+	for message, count := range c.counts {
+		if message != "" {
+			c.stats.Top = append(c.stats.Top, MessageCount{message, count})
+		}
+	}
+	sort.Slice(c.stats.Top, func(i, j int) bool {
+		a, b := c.stats.Top[i], c.stats.Top[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Message < b.Message
+	})
+	if len(c.stats.Top) > c.options.Top {
+		c.stats.Top = c.stats.Top[:c.options.Top]
+	}
+	for _, h := range c.hours {
+		c.stats.PerHour = append(c.stats.PerHour, *h)
+	}
+	sort.Slice(c.stats.PerHour, func(i, j int) bool {
+		return c.stats.PerHour[i].Hour.Before(c.stats.PerHour[j].Hour)
+	})
+	return c.stats
+}
+
+// AnalyzeLog reads a log file written by TimeWriter or JSONWriter, possibly
+// through a CollapsingWriter, and its rotated files, and summarizes them.
+// Entries without level, written by the standard log package, are counted
+// as errors or warnings when their message mentions them.
+func AnalyzeLog(filename string, options StatsOptions) (*LogStats, error) {
+	if options.Naming == nil {
+		options.Naming = TimeNaming{}
+	}
+	history, err := listRotated(filename, nil, options.Naming)
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewFollower(filename, FollowOptions{Naming: options.Naming})
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c := newStatsCollector(options)
+	c.stats.Files = append(history, filename)
+	for {
+		line, err := f.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.add(line)
+	}
+	stats := c.finish()
+	for _, err := range f.Skipped() {
+		stats.Skipped = append(stats.Skipped, err.Error())
+	}
+	return stats, nil
+}
+
+// WriteReport writes a human readable report of s to w.
+func (s *LogStats) WriteReport(w io.Writer) error {
+	const layout = "2006-01-02 15:04:05"
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "files: %d\n", len(s.Files))
+	for _, filename := range s.Files {
+		fmt.Fprintf(b, "  %s\n", filename)
+	}
+	fmt.Fprintf(b, "lines: %d\nentries: %d\n", s.Lines, s.Entries)
+	if s.Entries > 0 {
+		fmt.Fprintf(b, "first: %s\nlast: %s\n", s.First.Format(layout), s.Last.Format(layout))
+	}
+	fmt.Fprintf(b, "collapsed: %d summaries, %d repeated entries\n", s.Collapsed, s.Repeated)
+	fmt.Fprintf(b, "top messages:\n")
+	for _, m := range s.Top {
+		fmt.Fprintf(b, "  %8d %s\n", m.Count, m.Message)
+	}
+	fmt.Fprintf(b, "errors and warnings per hour:\n")
+	for _, h := range s.PerHour {
+		fmt.Fprintf(b, "  %s errors=%d warnings=%d\n", h.Hour.Format("2006-01-02 15:04"), h.Errors, h.Warnings)
+	}
+	fmt.Fprintf(b, "gaps:\n")
+	for _, g := range s.Gaps {
+		fmt.Fprintf(b, "  %s -> %s (%s)\n", g.From.Format(layout), g.To.Format(layout), g.To.Sub(g.From))
+	}
+	if len(s.Skipped) > 0 {
+		fmt.Fprintf(b, "skipped:\n")
+		for _, skipped := range s.Skipped {
+			fmt.Fprintf(b, "  %s\n", skipped)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
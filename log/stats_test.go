@@ -0,0 +1,91 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeLogSummarizesRotatedFiles(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	writeGzip(t, filepath.Join(dir, rotated1+".gz"),
+		"[2016-03-14 10:00:00] <sim> unit 12 lost contact\n"+
+			"[2016-03-14 10:00:01]  ...x5\n"+
+			"[2016-03-14 10:00:02] <sim> ERROR tick failed tick=3\n")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, rotated2), []byte(
+		"[2016-03-14 10:30:00] <sim> WARN slow tick\n"+
+			"[2016-03-14 11:00:00] panic: boom\n"+
+			"goroutine 1:\n"+
+			"[2016-03-14 11:00:01] <sim> unit 3..40 lost contact ...x3\n"), os.ModePerm))
+	stamp := func(sec int) string {
+		return time.Date(2016, 3, 14, 11, 0, sec, 0, time.Local).Format(time.RFC3339)
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filename), []byte(
+		`{"time":"`+stamp(30)+`","prefix":"sim","level":"ERROR","message":"tick failed"}`+"\n"+
+			`{"time":"`+stamp(31)+`","prefix":"sim","level":"ERROR","message":"tick failed","repeat":4}`+"\n"),
+		os.ModePerm))
+	stats, err := AnalyzeLog(filepath.Join(dir, filename), StatsOptions{
+		Top:         2,
+		Gap:         10 * time.Minute,
+		Normalizers: []*regexp.Regexp{Numbers},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, rotated1+".gz"),
+		filepath.Join(dir, rotated2),
+		filepath.Join(dir, filename),
+	}, stats.Files)
+	assert.Equal(t, 9, stats.Lines)
+	assert.Equal(t, 8, stats.Entries)
+	assert.Equal(t, time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local), stats.First)
+	assert.Equal(t, 3, stats.Collapsed)
+	assert.Equal(t, 4+2+3, stats.Repeated)
+	assert.Equal(t, []MessageCount{
+		{"<sim> unit # lost contact", 1 + 4 + 2},
+		{"<sim> ERROR tick failed", 1 + 1 + 3},
+	}, stats.Top)
+	assert.Len(t, stats.PerHour, 2)
+	assert.Equal(t, HourCount{time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local), 1, 1}, stats.PerHour[0])
+	assert.Equal(t, 1+1+3, stats.PerHour[1].Errors)
+	assert.Len(t, stats.Gaps, 2)
+	assert.Equal(t, Gap{
+		time.Date(2016, 3, 14, 10, 0, 2, 0, time.Local),
+		time.Date(2016, 3, 14, 10, 30, 0, 0, time.Local),
+	}, stats.Gaps[0])
+	buf := &bytes.Buffer{}
+	assert.NoError(t, stats.WriteReport(buf))
+	assert.Contains(t, buf.String(), "collapsed: 3 summaries, 9 repeated entries\n")
+	assert.Contains(t, buf.String(), "         7 <sim> unit # lost contact\n")
+	assert.Contains(t, buf.String(), "  2016-03-14 10:00:02 -> 2016-03-14 10:30:00 (29m58s)\n")
+}
+
+func TestAnalyzeLogSkipsUnreadableArchives(t *testing.T) {
+	dir := makeDir(t)
+	defer os.RemoveAll(dir)
+	zstd := filepath.Join(dir, rotated1+".zst")
+	assert.NoError(t, ioutil.WriteFile(zstd, []byte("zstd data"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filename), []byte(
+		"[2016-03-14 10:00:00] <sim> started\n"), os.ModePerm))
+	stats, err := AnalyzeLog(filepath.Join(dir, filename), StatsOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, []string{"open " + zstd + ": unsupported compression"}, stats.Skipped)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, stats.WriteReport(buf))
+	assert.Contains(t, buf.String(), "skipped:\n  open "+zstd+": unsupported compression\n")
+}
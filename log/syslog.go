@@ -0,0 +1,281 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Facility is a syslog facility code.
+type Facility int
+
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+)
+
+const (
+	FacilityLocal0 Facility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+var facilityNames = []string{"kern", "user", "mail", "daemon", "auth", "syslog",
+	"lpr", "news", "uucp", "cron", "authpriv", "ftp"}
+
+// ParseFacility converts a facility name like "user", "daemon" or "local0"
+// into a Facility.
+func ParseFacility(value string) (Facility, error) {
+	for i, name := range facilityNames {
+		if value == name && i != int(FacilityKern) {
+			return Facility(i), nil
+		}
+	}
+	if strings.HasPrefix(value, "local") && len(value) == len("local0") &&
+		value[5] >= '0' && value[5] <= '7' {
+		return FacilityLocal0 + Facility(value[5]-'0'), nil
+	}
+	return FacilityUser, fmt.Errorf("invalid syslog facility: %q", value)
+}
+
+// syslog severities, see RFC 5424 section 6.2.1.
+const (
+	severityError   = 3
+	severityWarning = 4
+	severityInfo    = 6
+	severityDebug   = 7
+)
+
+// SyslogFormat selects the syslog message format.
+type SyslogFormat int
+
+const (
+	RFC5424 SyslogFormat = iota
+	RFC3164
+)
+
+// SyslogConfig configures a SyslogWriter.
+type SyslogConfig struct {
+	// Network is "unix", "unixgram", "udp" or "tcp". An empty network
+	// connects to the local syslog daemon socket.
+	Network string
+	// Address of the syslog server or socket path.
+	Address string
+	// Facility defaults to FacilityUser, the kernel facility being reserved
+	// to the kernel.
+	Facility Facility
+	// AppName defaults to the executable name.
+	AppName  string
+	Format   SyslogFormat
+	Hostname string
+	// RetryInterval is the minimum delay between two reconnection attempts,
+	// it defaults to 10 seconds.
+	RetryInterval time.Duration
+}
+
+// SyslogWriter sends every log entry as a syslog message. Entry severities
+// are derived from Logger levels, entries without level are sent with the
+// informational severity. It reconnects after network failures and is safe
+// for concurrent use.
+type SyslogWriter struct {
+	mutex    sync.Mutex
+	config   SyslogConfig
+	conn     net.Conn
+	retry    time.Time
+	pid      string
+	hostname string
+}
+
+// ErrSyslogUnavailable is returned when writing while the syslog server
+// cannot be reached.
+var ErrSyslogUnavailable = errors.New("syslog server is unavailable")
+
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// NewSyslogWriter returns a SyslogWriter connected to the configured server.
+func NewSyslogWriter(config SyslogConfig) (*SyslogWriter, error) {
+	if config.AppName == "" {
+		config.AppName = strings.TrimSuffix(filepath.Base(os.Args[0]), filepath.Ext(os.Args[0]))
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 10 * time.Second
+	}
+	if config.Facility == FacilityKern {
+		config.Facility = FacilityUser
+	}
+	hostname := config.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	w := &SyslogWriter{
+		config:   config,
+		pid:      strconv.Itoa(os.Getpid()),
+		hostname: hostname,
+	}
+	err := w.connect()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *SyslogWriter) dial() (net.Conn, error) {
+	if w.config.Network != "" {
+		return net.Dial(w.config.Network, w.config.Address)
+	}
+	for _, address := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, address)
+			if err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, ErrSyslogUnavailable
+}
+
+func (w *SyslogWriter) connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	conn, err := w.dial()
+	if err != nil {
+		w.retry = now().Add(w.config.RetryInterval)
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *SyslogWriter) isStream() bool {
+	switch w.conn.(type) {
+	case *net.TCPConn:
+		return true
+	case *net.UnixConn:
+		return w.conn.LocalAddr().Network() == "unix"
+	}
+	return false
+}
+
+func severity(level string) int {
+	switch level {
+	case "DEBUG":
+		return severityDebug
+	case "WARN":
+		return severityWarning
+	case "ERROR":
+		return severityError
+	}
+	return severityInfo
+}
+
+// format builds a syslog message from a log entry.
+func (w *SyslogWriter) format(p []byte) []byte {
+	e := ParseEntry(string(p))
+	priority := int(w.config.Facility)*8 + severity(e.Level)
+	msg := strings.TrimRight(string(p), "\r\n")
+	if e.Prefix != "" {
+		msg = strings.TrimPrefix(msg, "<"+e.Prefix+"> ")
+	}
+	t := now()
+	buf := &bytes.Buffer{}
+	if w.config.Format == RFC3164 {
+		fmt.Fprintf(buf, "<%d>%s %s %s[%s]: %s", priority, t.Format(time.Stamp),
+			w.hostname, w.config.AppName, w.pid, msg)
+	} else {
+		// The log prefix is used as message identifier.
+		fmt.Fprintf(buf, "<%d>1 %s %s %s %s %s - %s", priority,
+			t.Format("2006-01-02T15:04:05.000000Z07:00"), nilValue(w.hostname),
+			nilValue(w.config.AppName), w.pid, nilValue(e.Prefix), msg)
+	}
+	return buf.Bytes()
+}
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.Replace(s, " ", "_", -1)
+}
+
+func (w *SyslogWriter) send(msg []byte) error {
+	if w.isStream() {
+		// Use octet counting framing, see RFC 6587 section 3.4.1.
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	_, err := w.conn.Write(msg)
+	return err
+}
+
+// Write sends p as a single syslog message. Messages written while the
+// server is unreachable are dropped and ErrSyslogUnavailable is returned.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.conn == nil {
+		if now().Before(w.retry) || w.connect() != nil {
+			return 0, ErrSyslogUnavailable
+		}
+	}
+	msg := w.format(p)
+	err := w.send(msg)
+	if err != nil {
+		// Reconnect once immediately, the server may have been restarted.
+		err = w.connect()
+		if err == nil {
+			err = w.send(msg)
+		}
+	}
+	if err != nil {
+		if w.conn != nil {
+			w.conn.Close()
+			w.conn = nil
+		}
+		w.retry = now().Add(w.config.RetryInterval)
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *SyslogWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.conn != nil {
+		err := w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package log
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestSyslogWriterSendsRFC5424OverUDP(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.UTC))
+	defer reset()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+	w, err := NewSyslogWriter(SyslogConfig{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: FacilityLocal0,
+		AppName:  "simulation",
+		Hostname: "host",
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+	l := NewLogger(w, "<sim> ", LevelDebug)
+	l.Error("lost contact", "unit", 12)
+	pid := strconv.Itoa(os.Getpid())
+	assert.Equal(t, "<131>1 2016-03-14T10:00:00.000000Z host simulation "+pid+
+		" sim - ERROR lost contact unit=12", readPacket(t, conn))
+	checkWrite(t, w)
+	assert.Equal(t, "<134>1 2016-03-14T10:00:00.000000Z host simulation "+pid+
+		" - - "+content, readPacket(t, conn))
+}
+
+func TestSyslogWriterSendsRFC3164(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 4, 10, 0, 0, 0, time.UTC))
+	defer reset()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+	w, err := NewSyslogWriter(SyslogConfig{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: FacilityDaemon,
+		AppName:  "simulation",
+		Hostname: "host",
+		Format:   RFC3164,
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+	_, err = w.Write([]byte("<sim> WARN overloaded\n"))
+	assert.NoError(t, err)
+	pid := strconv.Itoa(os.Getpid())
+	assert.Equal(t, "<28>Mar  4 10:00:00 host simulation["+pid+"]: WARN overloaded",
+		readPacket(t, conn))
+}
+
+func TestSyslogWriterDefaultsToUserFacility(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.UTC))
+	defer reset()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+	w, err := NewSyslogWriter(SyslogConfig{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		AppName:  "simulation",
+		Hostname: "host",
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+	_, err = w.Write([]byte("<sim> WARN overloaded\n"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(readPacket(t, conn), "<12>1 "))
+}
+
+func TestParseFacility(t *testing.T) {
+	for value, expected := range map[string]Facility{
+		"user":   FacilityUser,
+		"daemon": FacilityDaemon,
+		"ftp":    FacilityFtp,
+		"local0": FacilityLocal0,
+		"local7": FacilityLocal7,
+	} {
+		facility, err := ParseFacility(value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, facility)
+	}
+	for _, value := range []string{"", "kern", "local8", "local", "USER"} {
+		_, err := ParseFacility(value)
+		assert.Error(t, err)
+	}
+}
+
+func acceptMessages(t *testing.T, listener net.Listener, messages chan<- string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				length, err := r.ReadString(' ')
+				if err != nil {
+					return
+				}
+				n, err := strconv.Atoi(strings.TrimSpace(length))
+				assert.NoError(t, err)
+				buf := make([]byte, n)
+				_, err = r.Read(buf)
+				if err != nil {
+					return
+				}
+				messages <- string(buf)
+			}
+		}()
+	}
+}
+
+func TestSyslogWriterReconnectsOverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	messages := make(chan string, 10)
+	go acceptMessages(t, listener, messages)
+	w, err := NewSyslogWriter(SyslogConfig{
+		Network:       "tcp",
+		Address:       address,
+		AppName:       "simulation",
+		RetryInterval: time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+	_, err = w.Write([]byte("first\n"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(<-messages, " - - first"))
+	// Restart the server and drop existing connections.
+	listener.Close()
+	w.mutex.Lock()
+	w.conn.Close()
+	w.mutex.Unlock()
+	_, err = w.Write([]byte("lost\n"))
+	assert.Error(t, err)
+	listener, err = net.Listen("tcp", address)
+	assert.NoError(t, err)
+	defer listener.Close()
+	go acceptMessages(t, listener, messages)
+	time.Sleep(2 * time.Millisecond)
+	_, err = w.Write([]byte("second\n"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(<-messages, " - - second"))
+}
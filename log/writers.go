@@ -18,37 +18,347 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Time layouts of TimeWriter stamps.
+const (
+	DefaultTimeLayout = "2006-01-02 15:04:05"
+	MillisTimeLayout  = "2006-01-02 15:04:05.000"
+	// zoneLayout follows the default layouts of UTC stamps.
+	zoneLayout = "Z07:00"
+)
+
+// LineMode defines how TimeWriter stamps entries made of several lines.
+type LineMode int
+
+const (
+	// StampFirst only stamps the first line of entries.
+	StampFirst LineMode = iota
+	// StampEach stamps every line of entries.
+	StampEach
+	// IndentLines stamps the first line of entries and indents the
+	// following ones under it.
+	IndentLines
+)
+
+// TimeWriter prefixes entries with the current date and time.
 type TimeWriter struct {
 	writer io.Writer
+	layout string
+	utc    bool
+	// start is set when stamping the time elapsed since then.
+	start time.Time
+	lines LineMode
+}
+
+// TimeOption configures optional TimeWriter behaviours.
+type TimeOption func(*TimeWriter)
+
+// WithTimeLayout formats stamps with layout instead of DefaultTimeLayout,
+// like MillisTimeLayout or time.RFC3339.
+func WithTimeLayout(layout string) TimeOption {
+	return func(w *TimeWriter) {
+		w.layout = layout
+	}
+}
+
+// WithUTC stamps entries with UTC instead of local time. The default
+// layouts are then followed by a "Z" so that readers do not take the stamps
+// for local times.
+func WithUTC() TimeOption {
+	return func(w *TimeWriter) {
+		w.utc = true
+	}
+}
+
+// WithElapsed stamps entries with the time elapsed since the writer
+// creation, like "[+12.345s]". Log file readers like Follower or AnalyzeLog
+// do not recognize such stamps.
+func WithElapsed() TimeOption {
+	return func(w *TimeWriter) {
+		w.start = now()
+	}
+}
+
+// WithLineMode defines how entries made of several lines are stamped.
+func WithLineMode(mode LineMode) TimeOption {
+	return func(w *TimeWriter) {
+		w.lines = mode
+	}
+}
+
+// ParseTimeFormat converts a stamp format name into a TimeOption, valid
+// names are "default", "ms", "rfc3339", "rfc3339ms" and "elapsed". An empty
+// name selects the default format.
+func ParseTimeFormat(value string) (TimeOption, error) {
+	switch value {
+	case "", "default":
+		return WithTimeLayout(DefaultTimeLayout), nil
+	case "ms":
+		return WithTimeLayout(MillisTimeLayout), nil
+	case "rfc3339":
+		return WithTimeLayout(time.RFC3339), nil
+	case "rfc3339ms":
+		return WithTimeLayout("2006-01-02T15:04:05.000Z07:00"), nil
+	case "elapsed":
+		return WithElapsed(), nil
+	}
+	return nil, fmt.Errorf("invalid time format: %q", value)
+}
+
+// ParseLineMode converts "first", "each" or "indent" into a LineMode. An
+// empty value stands for "first".
+func ParseLineMode(value string) (LineMode, error) {
+	switch value {
+	case "", "first":
+		return StampFirst, nil
+	case "each":
+		return StampEach, nil
+	case "indent":
+		return IndentLines, nil
+	}
+	return StampFirst, fmt.Errorf("invalid line mode: %q", value)
+}
+
+// NewTimeWriter returns a TimeWriter writing to w.
+func NewTimeWriter(w io.Writer, options ...TimeOption) *TimeWriter {
+	t := &TimeWriter{writer: w, layout: DefaultTimeLayout}
+	for _, option := range options {
+		option(t)
+	}
+	return t
+}
+
+func (w TimeWriter) stamp() string {
+	t := now()
+	if !w.start.IsZero() {
+		return fmt.Sprintf("[+%.3fs] ", t.Sub(w.start).Seconds())
+	}
+	if w.utc {
+		t = t.UTC()
+	}
+	layout := w.layout
+	if layout == "" {
+		layout = DefaultTimeLayout
+	}
+	if w.utc && (layout == DefaultTimeLayout || layout == MillisTimeLayout) {
+		layout += zoneLayout
+	}
+	return "[" + t.Format(layout) + "] "
 }
 
 func (w TimeWriter) Write(p []byte) (int, error) {
-	date := time.Now().Format("[2006-01-02 15:04:05] ")
-	p = append([]byte(date), p...)
-	n, err := w.writer.Write(p)
-	if err != nil {
-		if n >= len(date) {
-			n = n - len(date)
-		} else {
-			n = 0
+	date := w.stamp()
+	buf := make([]byte, 0, len(date)+len(p))
+	buf = append(buf, date...)
+	if w.lines == StampFirst {
+		buf = append(buf, p...)
+	} else {
+		prefix := date
+		if w.lines == IndentLines {
+			prefix = strings.Repeat(" ", len(date))
 		}
+		rest := p
+		for len(rest) > 0 {
+			i := bytes.IndexByte(rest, '\n')
+			if i < 0 || i == len(rest)-1 {
+				buf = append(buf, rest...)
+				break
+			}
+			buf = append(buf, rest[:i+1]...)
+			buf = append(buf, prefix...)
+			rest = rest[i+1:]
+		}
+	}
+	n, err := w.writer.Write(buf)
+	// Report the written part of p, ignoring stamps.
+	n -= len(buf) - len(p)
+	if n < 0 {
+		n = 0
 	}
 	return n, err
 }
 
+// RotateWriter is an io.Writer rotating and pruning log files. It is safe for
+// concurrent use.
 type RotateWriter struct {
+	// stats and dropped, the bytes not written to the log file since the
+	// creation of the writer, are updated atomically and must stay first to
+	// be 64-bit aligned on 32-bit platforms.
+	stats    RotateStats
+	dropped  int64
+	mutex    sync.Mutex
 	filename string
 	maxFiles int
 	maxSize  int64
 	size     int64
 	inBytes  bool
-	file     *os.File
-	history  []string
+	// offset is the current file size and saved its size when the line
+	// count state was last saved, both are only maintained when counting
+	// lines.
+	offset     int64
+	saved      int64
+	file       *os.File
+	compressor Compressor
+	policy     RotatePolicy
+	next       time.Time
+	// started is the beginning of the rotation period of the log file.
+	started time.Time
+	// maxAge and maxTotalSize bound the age and cumulated size of rotated
+	// files, zero disables them.
+	maxAge       time.Duration
+	maxTotalSize int64
+	// historyLock guards history, compressErr and the compression queue
+	// which are updated by the background compression worker.
+	historyLock sync.Mutex
+	history     []string
+	compressErr error
+	queue       []string
+	compressing sync.WaitGroup
+	working     bool
+	// Hooks are called while the writer is locked and must not write to it.
+	onRotate   func(oldPath, newPath string)
+	onPrune    func(path string)
+	onOpen     func(w io.Writer)
+	onCompress func(path, compressed string)
+	// shared is true when other processes may write to the same log file,
+	// lock is then held while rotating or pruning.
+	shared bool
+	lock   *os.File
+	naming NamingScheme
+	// failure is the error which degraded the writer, nil otherwise. While
+	// degraded, entries go to fallback and reopening the log file is
+	// attempted again after retry.
+	failure       error
+	fallback      io.Writer
+	retryInterval time.Duration
+	retry         time.Time
+	notices       []string
+	// lost counts the bytes not written to the log file since the last
+	// failure.
+	lost int64
+}
+
+// RotateStats counts the activity of a RotateWriter since its creation.
+type RotateStats struct {
+	// Bytes and Lines count the data written to log files.
+	Bytes int64
+	Lines int64
+	// Rotations counts renamed log files and Prunes deleted rotated files.
+	Rotations int64
+	Prunes    int64
+	// Errors counts failures to open, write, rotate, prune or compress log
+	// files.
+	Errors int64
+	// Dropped is the number of bytes which could not be written to the log
+	// file.
+	Dropped int64
+}
+
+// RotateOption configures optional RotateWriter behaviours.
+type RotateOption func(*RotateWriter)
+
+// WithRotatePolicy rotates log files on the supplied schedule, in addition
+// to size based rotation.
+func WithRotatePolicy(policy RotatePolicy) RotateOption {
+	return func(w *RotateWriter) {
+		w.policy = policy
+	}
+}
+
+// WithMaxAge deletes rotated files last modified more than maxAge ago.
+func WithMaxAge(maxAge time.Duration) RotateOption {
+	return func(w *RotateWriter) {
+		w.maxAge = maxAge
+	}
+}
+
+// WithMaxTotalSize deletes the oldest rotated files until their cumulated
+// size, in bytes, fits in maxTotalSize. The current log file is not accounted
+// for.
+func WithMaxTotalSize(maxTotalSize int64) RotateOption {
+	return func(w *RotateWriter) {
+		w.maxTotalSize = maxTotalSize
+	}
+}
+
+// OnRotate calls f after the current log file got renamed into newPath.
+func OnRotate(f func(oldPath, newPath string)) RotateOption {
+	return func(w *RotateWriter) {
+		w.onRotate = f
+	}
+}
+
+// OnPrune calls f after a rotated file got deleted.
+func OnPrune(f func(path string)) RotateOption {
+	return func(w *RotateWriter) {
+		w.onPrune = f
+	}
+}
+
+// OnOpen calls f after a log file got opened, including the first one.
+// Data written to the supplied writer, like a header, goes to the log file
+// and is accounted for in its size.
+func OnOpen(f func(w io.Writer)) RotateOption {
+	return func(w *RotateWriter) {
+		w.onOpen = f
+	}
+}
+
+// OnCompress calls f after the rotated file path got compressed into
+// compressed and deleted. It is called from a background goroutine.
+func OnCompress(f func(path, compressed string)) RotateOption {
+	return func(w *RotateWriter) {
+		w.onCompress = f
+	}
+}
+
+// WithFileLock coordinates rotations with other processes writing to the
+// same log file using an advisory lock on a ".lock" file next to it. Only one
+// process rotates the file, others reopen the new one when they detect the
+// rotation. Sizes counted in lines only account for the lines written by
+// the current process.
+func WithFileLock() RotateOption {
+	return func(w *RotateWriter) {
+		w.shared = true
+	}
+}
+
+// WithNaming names rotated files after scheme instead of TimeNaming. Use a
+// SwitchedNaming to keep pruning files named after previous schemes.
+func WithNaming(scheme NamingScheme) RotateOption {
+	return func(w *RotateWriter) {
+		w.naming = scheme
+	}
+}
+
+// WithFallback writes entries to fallback, instead of os.Stderr, while the
+// log file cannot be written. A nil fallback discards them.
+func WithFallback(fallback io.Writer) RotateOption {
+	return func(w *RotateWriter) {
+		w.fallback = fallback
+	}
+}
+
+// WithRetryInterval sets the delay between attempts to reopen the log file
+// after a failure, it defaults to 10 seconds.
+func WithRetryInterval(interval time.Duration) RotateOption {
+	return func(w *RotateWriter) {
+		w.retryInterval = interval
+	}
+}
+
+// WithCompression compresses rotated files in the background using the
+// supplied compressor.
+func WithCompression(c Compressor) RotateOption {
+	return func(w *RotateWriter) {
+		w.compressor = c
+	}
 }
 
 func computeMaxSize(maxSize int64, sizeUnit string) int64 {
@@ -65,75 +375,353 @@ func (w *RotateWriter) computeSize(info os.FileInfo) int64 {
 	if w.inBytes {
 		return info.Size()
 	}
-	// Use an hard-coded line size to prevent time consuming
-	// start-ups when re-opening huge log files.
-	// see https://masagroup.atlassian.net/browse/SWBUG-14201
-	return info.Size() / 200
+	lines, err := countLines(w.filename, info)
+	if err != nil {
+		// Use an hard-coded line size to prevent time consuming
+		// start-ups when re-opening huge log files.
+		// see https://masagroup.atlassian.net/browse/SWBUG-14201
+		return info.Size() / 200
+	}
+	return lines
 }
 
 // NewRotateWriter creates a RotateWrite which handles rotating logs.
-func NewRotateWriter(filename string, maxFiles int, maxSize int64, sizeUnit string, truncate bool) (*RotateWriter, error) {
+func NewRotateWriter(filename string, maxFiles int, maxSize int64, sizeUnit string, truncate bool, options ...RotateOption) (*RotateWriter, error) {
 	w := &RotateWriter{
 		filename: filename,
 		maxFiles: maxFiles,
 		maxSize:  computeMaxSize(maxSize, sizeUnit),
 		inBytes:  sizeUnit == "bytes" || sizeUnit == "kbytes" || sizeUnit == "mbytes",
+		naming:   TimeNaming{},
+		fallback: os.Stderr,
+		// Retrying more often would flood the fallback with failures.
+		retryInterval: 10 * time.Second,
 	}
-	err := w.populate()
+	for _, option := range options {
+		option(w)
+	}
+	err := w.initialize(truncate)
 	if err != nil {
+		if w.lock != nil {
+			w.lock.Close()
+		}
 		return nil, err
 	}
+	w.compressHistory()
+	if w.maxFiles != 0 {
+		// Failing to open the log file at start-up is not a degradation.
+		w.mutex.Lock()
+		err = w.open()
+		w.mutex.Unlock()
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// initialize lists rotated files, truncates and prunes them.
+func (w *RotateWriter) initialize(truncate bool) error {
+	if w.shared {
+		lock, err := os.OpenFile(lockFilename(w.filename), os.O_CREATE+os.O_RDWR, os.ModePerm)
+		if err != nil {
+			return err
+		}
+		w.lock = lock
+	}
+	unlock, err := w.lockShared()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	err = w.populate()
+	if err != nil {
+		return err
+	}
 	info, err := os.Stat(w.filename)
 	if err == nil {
 		if info.IsDir() {
-			return nil, errors.New("invalid filename")
+			return errors.New("invalid filename")
 		}
 		w.size = w.computeSize(info)
+		w.offset = info.Size()
+		w.saved = w.offset
+		if w.policy != nil {
+			w.schedule(info.ModTime())
+		}
 		if truncate {
 			err = w.rotate()
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
-	err = w.prune()
-	if err != nil {
-		return nil, err
-	}
-	_, err = w.Write(nil)
-	if err != nil {
-		return nil, err
-	}
-	return w, nil
+	return w.prune()
 }
 
+// Close closes the current log file and waits for pending compressions.
 func (w *RotateWriter) Close() error {
+	err := w.close()
+	// Writes are not blocked while waiting.
+	w.compressing.Wait()
+	return err
+}
+
+func (w *RotateWriter) close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.lock != nil {
+		w.lock.Close()
+		w.lock = nil
+	}
 	if w.file != nil {
-		err := w.file.Close()
+		var err error
+		if !w.inBytes {
+			err = w.saveState()
+		}
+		cerr := w.file.Close()
 		w.file = nil
-		return err
+		if err != nil {
+			return err
+		}
+		return cerr
 	}
 	return nil
 }
 
-func (w *RotateWriter) populate() error {
-	dir := filepath.Dir(w.filename)
+// listRotated returns the rotated files of filename named after scheme,
+// oldest first. Files named after the previous schemes of a SwitchedNaming
+// come first.
+func listRotated(filename string, c Compressor, scheme NamingScheme) ([]string, error) {
+	dir := filepath.Dir(filename)
 	entries, err := ioutil.ReadDir(dir)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// Schemes are sorted from the oldest to the current one.
+	schemes := []NamingScheme{scheme}
+	if switched, ok := scheme.(SwitchedNaming); ok {
+		schemes = switched.schemes()
+		for i, j := 0, len(schemes)-1; i < j; i, j = i+1, j-1 {
+			schemes[i], schemes[j] = schemes[j], schemes[i]
+		}
 	}
-	stem := strings.TrimSuffix(filepath.Base(w.filename), filepath.Ext(w.filename))
-	regex := regexp.MustCompile(`^\Q` + stem + `\E\.\d{8}T\d{6}\.log(\.\d+)*$`)
+	type rotated struct {
+		name   string
+		scheme int
+		t      time.Time
+		seq    int
+	}
+	found := []rotated{}
 	for _, info := range entries {
-		filename := info.Name()
-		if regex.MatchString(filename) {
-			w.history = append(w.history, filepath.Join(dir, filename))
+		name := filepath.Join(dir, info.Name())
+		stripped := uncompressedName(name)
+		if c != nil {
+			stripped = uncompressedName(strings.TrimSuffix(name, c.Ext()))
+		}
+		for i := len(schemes) - 1; i >= 0; i-- {
+			t, seq, ok := schemes[i].Parse(filename, stripped)
+			if ok {
+				found = append(found, rotated{name, i, t, seq})
+				break
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.scheme != b.scheme {
+			return a.scheme < b.scheme
 		}
+		if !a.t.Equal(b.t) {
+			return a.t.Before(b.t)
+		}
+		return a.seq < b.seq
+	})
+	history := make([]string, 0, len(found))
+	for _, r := range found {
+		history = append(history, r.name)
+	}
+	return history, nil
+}
+
+func (w *RotateWriter) populate() error {
+	history, err := listRotated(w.filename, w.compressor, w.naming)
+	if err != nil {
+		return err
 	}
+	w.history = append(w.history, history...)
 	return nil
 }
 
+// compressHistory schedules the compression of rotated files left
+// uncompressed, for instance by a process killed while compressing.
+func (w *RotateWriter) compressHistory() {
+	if w.compressor == nil {
+		return
+	}
+	w.historyLock.Lock()
+	defer w.historyLock.Unlock()
+	for _, filename := range w.history {
+		if !isCompressed(filename, w.compressor) {
+			w.compress(filename)
+		}
+	}
+}
+
+// compress queues filename for compression by a single background worker,
+// so that large histories are compressed one file at a time. historyLock
+// must be held.
+func (w *RotateWriter) compress(filename string) {
+	w.queue = append(w.queue, filename)
+	if !w.working {
+		w.working = true
+		w.compressing.Add(1)
+		go w.compressQueued()
+	}
+}
+
+// nextQueued returns the next queued file still in the history, or false when
+// the queue is empty and the worker stops.
+func (w *RotateWriter) nextQueued() (string, bool) {
+	w.historyLock.Lock()
+	defer w.historyLock.Unlock()
+	for len(w.queue) > 0 {
+		filename := w.queue[0]
+		w.queue = w.queue[1:]
+		for _, f := range w.history {
+			if f == filename {
+				return filename, true
+			}
+		}
+		// The file was pruned while queued.
+	}
+	w.working = false
+	return "", false
+}
+
+func (w *RotateWriter) compressQueued() {
+	defer w.compressing.Done()
+	for {
+		filename, ok := w.nextQueued()
+		if !ok {
+			return
+		}
+		compressed, err := compressFile(w.compressor, filename)
+		if err != nil {
+			w.historyLock.Lock()
+			w.compressErr = err
+			w.historyLock.Unlock()
+			continue
+		}
+		if w.replace(filename, compressed) && w.onCompress != nil {
+			w.onCompress(filename, compressed)
+		}
+	}
+}
+
+// replace substitutes the compressed file to its source in the history and
+// returns true on success.
+func (w *RotateWriter) replace(filename, compressed string) bool {
+	w.historyLock.Lock()
+	defer w.historyLock.Unlock()
+	for i, f := range w.history {
+		if f == filename {
+			w.history[i] = compressed
+			os.Remove(filename)
+			return true
+		}
+	}
+	// The file was pruned while being compressed.
+	os.Remove(compressed)
+	return false
+}
+
+// rotatedByOther returns true if the log file is no longer the one written
+// to, which happens when another process rotated it.
+func (w *RotateWriter) rotatedByOther() bool {
+	if w.file == nil {
+		return false
+	}
+	current, err := w.file.Stat()
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(w.filename)
+	return err != nil || !os.SameFile(current, info)
+}
+
+// follow closes the log file when another process rotated it so that the
+// new one gets opened, or refreshes the size of the shared file.
+func (w *RotateWriter) follow() {
+	if w.rotatedByOther() {
+		w.file.Close()
+		w.file = nil
+		w.reset()
+		info, err := os.Stat(w.filename)
+		if err == nil {
+			w.size = w.computeSize(info)
+			w.offset = info.Size()
+			w.saved = w.offset
+		}
+		return
+	}
+	if w.inBytes {
+		info, err := w.file.Stat()
+		if err == nil {
+			w.size = info.Size()
+		}
+	}
+}
+
+func lockFilename(filename string) string {
+	return filename + ".lock"
+}
+
+// lockShared acquires the lock shared with other processes, if any, and
+// returns a function releasing it.
+func (w *RotateWriter) lockShared() (func(), error) {
+	if w.lock == nil {
+		return func() {}, nil
+	}
+	err := lockFile(w.lock)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		unlockFile(w.lock)
+	}, nil
+}
+
+// schedule sets the next rotation of a log file whose period started at t.
+func (w *RotateWriter) schedule(t time.Time) {
+	w.started = t
+	w.next = w.policy(t)
+}
+
+// rotationTime returns the time rotated files are named after. Files rotated
+// on schedule are named after the beginning of their period, so that a file
+// rotated at midnight is named after the day it holds.
+func (w *RotateWriter) rotationTime() time.Time {
+	if w.policy != nil && !w.next.IsZero() && !now().Before(w.next) {
+		return w.started
+	}
+	return now()
+}
+
+func (w *RotateWriter) reset() {
+	w.size = 0
+	w.offset = 0
+	w.saved = 0
+	w.next = time.Time{}
+}
+
 func (w *RotateWriter) rotate() error {
+	if w.shared && w.rotatedByOther() {
+		w.follow()
+		return nil
+	}
+	rotated := w.rotationTime()
 	if w.file != nil {
 		err := w.file.Close()
 		w.file = nil
@@ -141,53 +729,131 @@ func (w *RotateWriter) rotate() error {
 			return err
 		}
 	}
-	w.size = 0
+	w.reset()
+	if !w.inBytes {
+		os.Remove(stateFile(w.filename))
+	}
 	_, err := os.Stat(w.filename)
 	if err == nil {
-		filename := appendSuffixToFile(w.filename)
-		err := os.Rename(w.filename, filename)
+		w.historyLock.Lock()
+		err := w.refreshHistory()
+		filename := w.naming.Rotated(w.filename, rotated, w.history)
+		w.historyLock.Unlock()
 		if err != nil {
 			return err
 		}
+		err = os.Rename(w.filename, filename)
+		if err != nil {
+			return err
+		}
+		w.historyLock.Lock()
 		w.history = append(w.history, filename)
+		atomic.AddInt64(&w.stats.Rotations, 1)
+		if w.compressor != nil {
+			w.compress(filename)
+		}
+		w.historyLock.Unlock()
+		if w.onRotate != nil {
+			w.onRotate(w.filename, filename)
+		}
 	}
 	return nil
 }
 
-func appendSuffixToFile(from string) string {
-	ext := filepath.Ext(from)
-	filename := strings.TrimSuffix(from, ext) +
-		"." + time.Now().Format("20060102T150405") + ext
-	suffix := 0
-	to := filename
-	_, err := os.Stat(to)
-	for err == nil {
-		suffix++
-		to = filename + "." + strconv.Itoa(suffix)
-		_, err = os.Stat(to)
+// refreshHistory lists rotated files again when other processes may have
+// rotated or pruned some. historyLock must be held.
+func (w *RotateWriter) refreshHistory() error {
+	if !w.shared {
+		return nil
+	}
+	history, err := listRotated(w.filename, w.compressor, w.naming)
+	if err != nil {
+		return err
 	}
-	return to
+	w.history = history
+	return nil
 }
 
+// prune deletes the oldest rotated files until the history satisfies the
+// maximum number of files, maximum age and total size constraints.
 func (w *RotateWriter) prune() error {
-	if w.maxFiles < 0 {
+	w.historyLock.Lock()
+	defer w.historyLock.Unlock()
+	err := w.refreshHistory()
+	if err != nil {
+		return err
+	}
+	for w.maxFiles >= 0 && len(w.history) >= w.maxFiles && len(w.history) > 0 {
+		err := w.remove(0)
+		if err != nil {
+			return err
+		}
+	}
+	if w.maxAge <= 0 && w.maxTotalSize <= 0 {
 		return nil
 	}
-	for len(w.history) >= w.maxFiles && len(w.history) > 0 {
-		err := os.Remove(w.history[0])
+	sizes := make([]int64, 0, len(w.history))
+	total := int64(0)
+	oldest := now().Add(-w.maxAge)
+	for i := 0; i < len(w.history); {
+		info, err := os.Stat(w.history[i])
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err != nil || w.maxAge > 0 && info.ModTime().Before(oldest) {
+			err = w.remove(i)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		sizes = append(sizes, info.Size())
+		total += info.Size()
+		i++
+	}
+	for w.maxTotalSize > 0 && total > w.maxTotalSize && len(w.history) > 0 {
+		err := w.remove(0)
 		if err != nil {
 			return err
 		}
-		w.history = w.history[1:]
+		total -= sizes[0]
+		sizes = sizes[1:]
+	}
+	return nil
+}
+
+// remove deletes the i-th rotated file and drops it from the history.
+func (w *RotateWriter) remove(i int) error {
+	filename := w.history[i]
+	err := os.Remove(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	w.history = append(w.history[:i], w.history[i+1:]...)
+	if err != nil {
+		return nil
+	}
+	atomic.AddInt64(&w.stats.Prunes, 1)
+	if w.onPrune != nil {
+		w.onPrune(filename)
 	}
 	return nil
 }
 
+func (w *RotateWriter) takeCompressError() error {
+	w.historyLock.Lock()
+	defer w.historyLock.Unlock()
+	err := w.compressErr
+	w.compressErr = nil
+	return err
+}
+
 func (w *RotateWriter) increaseSize(size int) {
 	if w.inBytes {
 		w.size += int64(size)
 	} else {
 		w.size++
+		w.offset += int64(size)
 	}
 }
 
@@ -196,59 +862,444 @@ func lineCount(p []byte) int64 {
 	return int64(bytes.Count(p, lineSep))
 }
 
+// mustRotate returns true if the current log file is either too large or
+// out of its rotation period. Empty files are never rotated on schedule.
+func (w *RotateWriter) mustRotate() bool {
+	if w.maxSize > 0 && w.size >= w.maxSize {
+		return true
+	}
+	if w.policy == nil || w.next.IsZero() || now().Before(w.next) {
+		return false
+	}
+	if w.size == 0 {
+		w.schedule(now())
+		return false
+	}
+	return true
+}
+
 func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
 	if w.maxFiles == 0 {
 		return len(p), nil
 	}
-	if w.maxSize > 0 && w.size >= w.maxSize && w.file != nil {
-		err := w.rotate()
+	if w.file != nil && w.shared {
+		w.follow()
+	}
+	if w.file != nil && w.mustRotate() {
+		unlock, err := w.lockShared()
+		if err == nil {
+			err = w.rotate()
+		}
 		if err != nil {
-			size, _ := fmt.Fprintf(w.file, "failed to rotate log file: %s\n", err)
-			w.increaseSize(size)
+			w.notify("failed to rotate log file: %s\n", err)
 		}
 		err = w.prune()
 		if err != nil {
-			size, _ := fmt.Fprintf(w.file, "failed to prune log file: %s\n", err)
-			w.increaseSize(size)
+			w.notify("failed to prune log file: %s\n", err)
+		}
+		if unlock != nil {
+			unlock()
 		}
 	}
-	if w.file == nil {
-		file, err := os.OpenFile(w.filename, os.O_CREATE+os.O_WRONLY+os.O_APPEND, os.ModePerm)
+	if w.file == nil && (w.failure == nil || !now().Before(w.retry)) {
+		err := w.open()
 		if err != nil {
-			return 0, err
+			w.degrade(err)
+		}
+	}
+	if err := w.takeCompressError(); err != nil {
+		w.notify("failed to compress log file: %s\n", err)
+	}
+	for _, notice := range w.notices {
+		if w.file != nil {
+			w.write([]byte(notice))
+		} else if w.fallback != nil {
+			io.WriteString(w.fallback, notice)
 		}
-		w.file = file
+	}
+	w.notices = nil
+	return w.write(p)
+}
+
+// open opens the log file and reports a recovery from a failure in it.
+func (w *RotateWriter) open() error {
+	file, err := openAppend(w.filename)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	if w.policy != nil && w.next.IsZero() {
+		w.schedule(now())
+	}
+	if w.onOpen != nil {
+		w.onOpen(openWriter{w})
+	}
+	if w.failure != nil && w.file != nil {
+		notice := fmt.Sprintf("recovered from log file failure, %d bytes were not written: %s\n",
+			w.lost, w.failure)
+		w.failure = nil
+		w.lost = 0
+		w.write([]byte(notice))
+	}
+	return nil
+}
+
+// notify reports a failure in the log file, or in the fallback writer while
+// it cannot be written.
+func (w *RotateWriter) notify(format string, args ...interface{}) {
+	atomic.AddInt64(&w.stats.Errors, 1)
+	w.notices = append(w.notices, fmt.Sprintf(format, args...))
+}
+
+// degrade closes the log file after err and writes to the fallback writer
+// until reopening it succeeds.
+func (w *RotateWriter) degrade(err error) {
+	atomic.AddInt64(&w.stats.Errors, 1)
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+	if w.failure == nil {
+		w.failure = err
+		if w.fallback != nil {
+			fmt.Fprintf(w.fallback, "failed to write log file %s, falling back until it recovers: %s\n",
+				w.filename, err)
+		}
+	}
+	w.retry = now().Add(w.retryInterval)
+}
+
+// Dropped returns the number of bytes which could not be written to the log
+// file, whether they reached the fallback writer or not.
+func (w *RotateWriter) Dropped() int64 {
+	return atomic.LoadInt64(&w.dropped)
+}
+
+// Stats returns the counters of the writer.
+func (w *RotateWriter) Stats() RotateStats {
+	return RotateStats{
+		Bytes:     atomic.LoadInt64(&w.stats.Bytes),
+		Lines:     atomic.LoadInt64(&w.stats.Lines),
+		Rotations: atomic.LoadInt64(&w.stats.Rotations),
+		Prunes:    atomic.LoadInt64(&w.stats.Prunes),
+		Errors:    atomic.LoadInt64(&w.stats.Errors),
+		Dropped:   atomic.LoadInt64(&w.dropped),
+	}
+}
+
+// Failure returns the error which prevents writing to the log file, or nil
+// if the writer is not degraded.
+func (w *RotateWriter) Failure() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.failure
+}
+
+// openWriter lets OnOpen hooks write to the log file while it is locked.
+type openWriter struct {
+	w *RotateWriter
+}
+
+func (o openWriter) Write(p []byte) (int, error) {
+	return o.w.write(p)
+}
+
+// write writes p to the log file, or to the fallback writer if it is not
+// available. Write failures degrade the writer instead of being returned.
+func (w *RotateWriter) write(p []byte) (int, error) {
+	if w.file == nil {
+		w.writeFallback(p)
+		return len(p), nil
 	}
 	size, err := w.file.Write(p)
+	atomic.AddInt64(&w.stats.Bytes, int64(size))
+	atomic.AddInt64(&w.stats.Lines, lineCount(p[:size]))
 	if w.inBytes {
 		w.increaseSize(size)
 	} else {
-		w.size += lineCount(p)
+		w.size += lineCount(p[:size])
+		w.offset += int64(size)
+		if w.offset-w.saved >= stateInterval {
+			w.saveState()
+		}
 	}
-	return size, err
+	if err != nil {
+		w.degrade(err)
+		w.writeFallback(p[size:])
+	}
+	return len(p), nil
 }
 
+func (w *RotateWriter) writeFallback(p []byte) {
+	w.lost += int64(len(p))
+	atomic.AddInt64(&w.dropped, int64(len(p)))
+	if w.fallback != nil {
+		w.fallback.Write(p)
+	}
+}
+
+// RepeatWriter is implemented by writers reporting collapsed entries by
+// themselves instead of receiving a " ...xN" line.
+type RepeatWriter interface {
+	// WriteRepeat reports that entry p occurred count times in a row.
+	WriteRepeat(p []byte, count int) (int, error)
+}
+
+// CollapsingWriter packs consecutive duplicate messages into a single entry
+// followed by the number of occurrences. It is safe for concurrent use.
 type CollapsingWriter struct {
+	mutex sync.Mutex
 	w     io.Writer
-	last  []byte
+	// entries holds the last distinct messages, most recent last, and
+	// window their maximum number.
+	entries  []*collapsed
+	window   int
+	interval time.Duration
+	timer    *time.Timer
+	// normalizers match the variable parts of messages, pattern combines
+	// them.
+	normalizers []*regexp.Regexp
+	pattern     *regexp.Regexp
+	stats       CollapseStats
+}
+
+// CollapseStats counts the entries of a CollapsingWriter since its creation.
+type CollapseStats struct {
+	// Entries counts the entries received, Collapsed the repeats which were
+	// not written and Summaries the repeat counts written instead.
+	Entries   int64
+	Collapsed int64
+	Summaries int64
+}
+
+type collapsed struct {
+	data  []byte
 	count int
+	// key holds the constant parts of data around its variable parts, first
+	// and last hold the variable parts of the first and last collapsed
+	// messages.
+	key   [][]byte
+	first [][]byte
+	last  [][]byte
+}
+
+// CollapseOption configures optional CollapsingWriter behaviours.
+type CollapseOption func(*CollapsingWriter)
+
+// WithFlushInterval writes pending repeat counts at most interval after
+// they started accumulating, instead of waiting for a different message.
+func WithFlushInterval(interval time.Duration) CollapseOption {
+	return func(w *CollapsingWriter) {
+		w.interval = interval
+	}
+}
+
+// WithWindow collapses repeats of any of the last size distinct messages,
+// so that interleaved messages get collapsed too. Their counts are written
+// along a copy of the message when they leave the window or get flushed.
+func WithWindow(size int) CollapseOption {
+	return func(w *CollapsingWriter) {
+		w.window = size
+	}
+}
+
+// WithNormalizer collapses messages which only differ by parts matching
+// the supplied regular expression, like tick numbers or identifiers. The
+// collapsed entry reports the first and last values of differing parts, as
+// in "unit 12..873 lost contact ...x40".
+func WithNormalizer(re *regexp.Regexp) CollapseOption {
+	return func(w *CollapsingWriter) {
+		w.normalizers = append(w.normalizers, re)
+	}
+}
+
+var (
+	// Numbers matches decimal numbers.
+	Numbers = regexp.MustCompile(`\d+`)
+	// HexIDs matches 0x prefixed hexadecimal numbers and identifiers made of
+	// at least 8 hexadecimal digits.
+	HexIDs = regexp.MustCompile(`0x[0-9a-fA-F]+|\b[0-9a-fA-F]{8,}\b`)
+)
+
+// alternatives returns a regular expression matching any of res.
+func alternatives(res []*regexp.Regexp) string {
+	patterns := make([]string, 0, len(res))
+	for _, re := range res {
+		patterns = append(patterns, "(?:"+re.String()+")")
+	}
+	return strings.Join(patterns, "|")
+}
+
+// NewCollapsingWriter returns a CollapsingWriter writing to w.
+func NewCollapsingWriter(w io.Writer, options ...CollapseOption) *CollapsingWriter {
+	c := &CollapsingWriter{w: w}
+	for _, option := range options {
+		option(c)
+	}
+	if len(c.normalizers) > 0 {
+		c.pattern = regexp.MustCompile(alternatives(c.normalizers))
+	}
+	return c
+}
+
+// normalize returns p with its variable parts masked, and these parts.
+func (w *CollapsingWriter) normalize(p []byte) ([][]byte, [][]byte) {
+	if w.pattern == nil {
+		return [][]byte{append([]byte(nil), p...)}, nil
+	}
+	key := [][]byte{}
+	values := [][]byte{}
+	start := 0
+	for _, match := range w.pattern.FindAllIndex(p, -1) {
+		key = append(key, append([]byte(nil), p[start:match[0]]...))
+		values = append(values, append([]byte(nil), p[match[0]:match[1]]...))
+		start = match[1]
+	}
+	return append(key, append([]byte(nil), p[start:]...)), values
+}
+
+func equalParts(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *CollapsingWriter) find(key [][]byte) int {
+	for i, e := range w.entries {
+		if equalParts(e.key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// render returns the collapsed message with its differing variable parts
+// replaced by their first and last values, and whether any part differed.
+func (w *CollapsingWriter) render(e *collapsed) ([]byte, bool) {
+	if w.pattern == nil || e.count == 0 {
+		return e.data, false
+	}
+	rendered := []byte{}
+	differs := false
+	for i, part := range e.key {
+		if i > 0 {
+			first, last := e.first[i-1], e.last[i-1]
+			rendered = append(rendered, first...)
+			if !bytes.Equal(first, last) {
+				differs = true
+				rendered = append(append(rendered, ".."...), last...)
+			}
+		}
+		rendered = append(rendered, part...)
+	}
+	return rendered, differs
+}
+
+func (w *CollapsingWriter) writeCount(e *collapsed) {
+	if e.count == 0 {
+		return
+	}
+	data, differs := w.render(e)
+	w.stats.Summaries++
+	if r, ok := w.w.(RepeatWriter); ok {
+		r.WriteRepeat(data, e.count+1)
+	} else if w.window > 1 || differs {
+		fmt.Fprintf(w.w, "%s ...x%d\n", bytes.TrimRight(data, "\n"), e.count+1)
+	} else {
+		fmt.Fprintf(w.w, " ...x%d\n", e.count+1)
+	}
+	e.count = 0
 }
 
 func (w *CollapsingWriter) Write(p []byte) (int, error) {
-	if bytes.Equal(w.last, p) {
-		w.count++
-		return 0, nil
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.stats.Entries++
+	key, values := w.normalize(p)
+	if i := w.find(key); i >= 0 {
+		e := w.entries[i]
+		e.count++
+		w.stats.Collapsed++
+		e.last = values
+		w.entries = append(append(w.entries[:i], w.entries[i+1:]...), e)
+		if w.interval > 0 && w.timer == nil {
+			w.timer = time.AfterFunc(w.interval, w.onTimer)
+		}
+		// Report the repeat as written so that io.MultiWriter keeps
+		// forwarding it to the other outputs.
+		return len(p), nil
 	}
-	if w.count > 0 {
-		fmt.Fprintf(w.w, " ...x%d\n", w.count+1)
-		w.count = 0
+	window := w.window
+	if window < 1 {
+		window = 1
 	}
-	w.last = make([]byte, len(p))
-	copy(w.last, p)
+	for len(w.entries) >= window {
+		w.writeCount(w.entries[0])
+		w.entries = w.entries[1:]
+	}
+	w.entries = append(w.entries, &collapsed{
+		data:  append([]byte(nil), p...),
+		key:   key,
+		first: values,
+		last:  values,
+	})
 	n, err := w.w.Write(p)
 	return n, err
 }
 
-func MakeCollapsingWriter(w io.Writer) io.Writer {
-	return &CollapsingWriter{w: &TimeWriter{w}}
+func (w *CollapsingWriter) onTimer() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timer != nil {
+		w.timer = nil
+		w.flush()
+	}
+}
+
+func (w *CollapsingWriter) flush() {
+	for _, e := range w.entries {
+		w.writeCount(e)
+	}
+	// Forget flushed messages so that the next occurrence gets written in
+	// full instead of starting an unrelated repeat count.
+	w.entries = nil
+}
+
+// Flush writes pending repeat counts.
+func (w *CollapsingWriter) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.flush()
+	return nil
+}
+
+// Stats returns the counters of the writer.
+func (w *CollapsingWriter) Stats() CollapseStats {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.stats
+}
+
+// Close flushes pending repeat counts and stops the flush timer. It does not
+// close the underlying writer.
+func (w *CollapsingWriter) Close() error {
+	return w.Flush()
+}
+
+// MakeCollapsingWriter returns a CollapsingWriter timestamping entries
+// written to w.
+func MakeCollapsingWriter(w io.Writer, options ...CollapseOption) *CollapsingWriter {
+	return NewCollapsingWriter(NewTimeWriter(w), options...)
 }
@@ -17,7 +17,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -61,6 +63,74 @@ func readFiles(t *testing.T, dir string) []string {
 	return files
 }
 
+func TestTimeWriterStampsEntries(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 123456789, time.Local))
+	defer reset()
+	panic := "panic: failed\ngoroutine 1:\nmain.main()\n"
+	for _, c := range []struct {
+		options  []TimeOption
+		expected string
+	}{
+		{nil, "[2016-03-14 10:00:00] panic: failed\ngoroutine 1:\nmain.main()\n"},
+		{[]TimeOption{WithLineMode(StampEach)},
+			"[2016-03-14 10:00:00] panic: failed\n" +
+				"[2016-03-14 10:00:00] goroutine 1:\n" +
+				"[2016-03-14 10:00:00] main.main()\n"},
+		{[]TimeOption{WithLineMode(IndentLines), WithTimeLayout(MillisTimeLayout)},
+			"[2016-03-14 10:00:00.123] panic: failed\n" +
+				"                          goroutine 1:\n" +
+				"                          main.main()\n"},
+		{[]TimeOption{WithUTC()},
+			"[" + now().UTC().Format(DefaultTimeLayout) + "Z] panic: failed\ngoroutine 1:\nmain.main()\n"},
+		{[]TimeOption{WithTimeLayout(time.RFC3339), WithUTC()},
+			"[" + now().UTC().Format(time.RFC3339) + "] panic: failed\ngoroutine 1:\nmain.main()\n"},
+	} {
+		buf := &bytes.Buffer{}
+		n, err := NewTimeWriter(buf, c.options...).Write([]byte(panic))
+		assert.NoError(t, err)
+		assert.Equal(t, len(panic), n)
+		assert.Equal(t, c.expected, buf.String())
+	}
+}
+
+func TestTimeWriterStampsElapsedTime(t *testing.T) {
+	advance, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local))
+	defer reset()
+	buf := &bytes.Buffer{}
+	w := NewTimeWriter(buf, WithElapsed(), WithLineMode(StampEach))
+	advance(1500 * time.Millisecond)
+	w.Write([]byte("a\nb"))
+	assert.Equal(t, "[+1.500s] a\n[+1.500s] b", buf.String())
+}
+
+func TestParseTimeStamps(t *testing.T) {
+	ref := time.Date(2016, 3, 14, 10, 0, 0, 0, time.Local)
+	for _, format := range []string{"", "ms", "rfc3339", "rfc3339ms"} {
+		option, err := ParseTimeFormat(format)
+		assert.NoError(t, err)
+		for _, utc := range []bool{false, true} {
+			options := []TimeOption{option}
+			if utc {
+				options = append(options, WithUTC())
+			}
+			buf := &bytes.Buffer{}
+			_, reset := setClock(ref)
+			NewTimeWriter(buf, options...).Write([]byte("entry"))
+			reset()
+			stamp, ok := parseTimestamp(buf.String())
+			assert.True(t, ok, format)
+			assert.True(t, ref.Equal(stamp), format)
+		}
+	}
+	_, err := ParseTimeFormat("iso")
+	assert.Error(t, err)
+	mode, err := ParseLineMode("indent")
+	assert.NoError(t, err)
+	assert.Equal(t, IndentLines, mode)
+	_, err = ParseLineMode("all")
+	assert.Error(t, err)
+}
+
 func TestRotatingLogInvalidFilenameReturnsErrorUponCreation(t *testing.T) {
 	dir := makeDir(t)
 	w, err := NewRotateWriter(filepath.Join(dir, ".."), 0, 3, "bytes", true)
@@ -284,6 +354,41 @@ func TestRotatingLogCountsLinesWell(t *testing.T) {
 	checkContent(t, filepath.Join(dir, files[1]), someline)
 }
 
+func TestRotatingLogPrunesFilesOlderThanMaxAge(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	old := time.Now().Add(-15 * 24 * time.Hour)
+	err := os.Chtimes(filepath.Join(dir, rotated1), old, old)
+	assert.NoError(t, err)
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, 3, "bytes", false,
+		WithMaxAge(14*24*time.Hour))
+	assert.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, []string{rotated2, unrelated1, filename, unrelated2, unrelated3},
+		readFiles(t, dir))
+}
+
+func TestRotatingLogPrunesFilesExceedingMaxTotalSize(t *testing.T) {
+	dir := makeFiles(t)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{rotated1, rotated2} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm)
+		assert.NoError(t, err)
+	}
+	w, err := NewRotateWriter(filepath.Join(dir, filename), -1, int64(len(content)), "bytes", false,
+		WithMaxTotalSize(int64(2*len(content))))
+	assert.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, []string{rotated1, rotated2, unrelated1, filename, unrelated2, unrelated3},
+		readFiles(t, dir))
+	checkWrite(t, w)
+	checkWrite(t, w)
+	files := readFiles(t, dir)
+	assert.Len(t, files, 6)
+	assert.NotContains(t, files, rotated1)
+	assert.Contains(t, files, rotated2)
+}
+
 func TestCollapsingLog(t *testing.T) {
 	b := bytes.Buffer{}
 	w := CollapsingWriter{w: &b}
@@ -295,11 +400,11 @@ func TestCollapsingLog(t *testing.T) {
 	b.Reset()
 	n, err = w.Write(m)
 	assert.NoError(t, err)
-	assert.Equal(t, n, 0)
+	assert.Equal(t, n, len(m))
 	assert.Equal(t, b.String(), "")
 	n, err = w.Write(m)
 	assert.NoError(t, err)
-	assert.Equal(t, n, 0)
+	assert.Equal(t, n, len(m))
 	assert.Equal(t, b.String(), "")
 	m2 := []byte("another message")
 	n, err = w.Write(m2)
@@ -312,3 +417,134 @@ func TestCollapsingLog(t *testing.T) {
 	assert.Equal(t, n, len(m))
 	assert.Equal(t, b.String(), "message")
 }
+
+func TestCollapsingLogsBehindMultiWriter(t *testing.T) {
+	a, b := bytes.Buffer{}, bytes.Buffer{}
+	ca, cb := NewCollapsingWriter(&a), NewCollapsingWriter(&b)
+	w := io.MultiWriter(ca, cb)
+	for _, m := range []string{"same\n", "same\n", "same\n", "other\n"} {
+		n, err := w.Write([]byte(m))
+		assert.NoError(t, err)
+		assert.Equal(t, len(m), n)
+	}
+	assert.Equal(t, "same\n ...x3\nother\n", a.String())
+	assert.Equal(t, "same\n ...x3\nother\n", b.String())
+	s := NewSamplingWriter(ca, 10, 0)
+	defer s.Close()
+	n, err := s.Write([]byte("other\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, len("other\n"), n)
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mutex sync.Mutex
+	b     bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.b.String()
+}
+
+func TestCollapsingLogFlushesPendingRepeats(t *testing.T) {
+	b := bytes.Buffer{}
+	w := NewCollapsingWriter(&b)
+	m := []byte("message\n")
+	for i := 0; i < 3; i++ {
+		_, err := w.Write(m)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "message\n ...x3\n", b.String())
+	assert.NoError(t, w.Flush())
+	_, err := w.Write(m)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "message\n ...x3\nmessage\n", b.String())
+}
+
+func TestCollapsingLogFlushesPeriodically(t *testing.T) {
+	b := &lockedBuffer{}
+	w := NewCollapsingWriter(b, WithFlushInterval(10*time.Millisecond))
+	defer w.Close()
+	m := []byte("message\n")
+	for i := 0; i < 5; i++ {
+		_, err := w.Write(m)
+		assert.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		return b.String() == "message\n ...x5\n"
+	}, time.Second, time.Millisecond)
+}
+
+func TestCollapsingLogCollapsesInterleavedMessages(t *testing.T) {
+	b := bytes.Buffer{}
+	w := NewCollapsingWriter(&b, WithWindow(2))
+	for i := 0; i < 3; i++ {
+		_, err := w.Write([]byte("first\n"))
+		assert.NoError(t, err)
+		_, err = w.Write([]byte("second\n"))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "first\nsecond\n", b.String())
+	_, err := w.Write([]byte("third\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "first\nsecond\nfirst ...x3\nthird\n", b.String())
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "first\nsecond\nfirst ...x3\nthird\nsecond ...x3\n", b.String())
+}
+
+func TestCollapsingLogCollapsesNormalizedMessages(t *testing.T) {
+	b := bytes.Buffer{}
+	w := NewCollapsingWriter(&b, WithNormalizer(Numbers), WithNormalizer(HexIDs))
+	for _, m := range []string{
+		"unit 12 lost contact with deadbeef01\n",
+		"unit 13 lost contact with deadbeef01\n",
+		"unit 873 lost contact with deadbeef01\n",
+		"tick 1\n",
+		"tick 1\n",
+		"unit 12 lost contact\n",
+	} {
+		_, err := w.Write([]byte(m))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "unit 12 lost contact with deadbeef01\n"+
+		"unit 12..873 lost contact with deadbeef01 ...x3\n"+
+		"tick 1\n"+
+		" ...x2\n"+
+		"unit 12 lost contact\n", b.String())
+}
+
+func TestCollapsingLogNormalizesMessagesWithNulBytes(t *testing.T) {
+	b := bytes.Buffer{}
+	w := NewCollapsingWriter(&b, WithNormalizer(Numbers))
+	w.Write([]byte("a\x00b 1\n"))
+	w.Write([]byte("a\x00b 2\n"))
+	w.Write([]byte("a 1\x00b\n"))
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "a\x00b 1\na\x00b 1..2 ...x2\na 1\x00b\n", b.String())
+}
+
+func TestCollapsingLogReportsNormalizedRepeatsToJSON(t *testing.T) {
+	_, reset := setClock(time.Date(2016, 3, 14, 10, 0, 0, 0, time.UTC))
+	defer reset()
+	b := bytes.Buffer{}
+	w := MakeJSONCollapsingWriter(&b, WithNormalizer(Numbers))
+	for i := 1; i <= 3; i++ {
+		_, err := w.Write([]byte("tick " + strings.Repeat("1", i) + " done\n"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Flush())
+	assert.Equal(t,
+		`{"time":"2016-03-14T10:00:00Z","message":"tick 1 done"}`+"\n"+
+			`{"time":"2016-03-14T10:00:00Z","message":"tick 1..111 done","repeat":3}`+"\n",
+		b.String())
+}
@@ -0,0 +1,43 @@
+// ****************************************************************************
+//
+// This file is part of a MASA library or program.
+// Refer to the included end-user license agreement for restrictions.
+//
+// Copyright (c) 2026 MASA Group
+//
+// ****************************************************************************
+
+package util
+
+import (
+	"flag"
+	"github.com/masagroup/sw.golibs/config"
+	masalog "github.com/masagroup/sw.golibs/log"
+	"io"
+)
+
+// CollectBundle writes to w a bug report archive, see masalog.CollectBundle,
+// with the start-up log and the log file configured by Parse, including its
+// rotated files, the configuration file and system information. Sensitive
+// values are masked unless options supply another redactor.
+func CollectBundle(w io.Writer, logPrefix string, cfg *config.Config, options masalog.BundleOptions) error {
+	options.Logs = append(options.Logs, debugLogPath(logPrefix))
+	if f := flag.Lookup("log"); f != nil && f.Value.String() != "" {
+		options.Logs = append(options.Logs, f.Value.String())
+	}
+	if f := flag.Lookup("log-naming"); f != nil && options.Naming == nil {
+		naming, err := masalog.ParseNamingScheme(f.Value.String())
+		if err != nil {
+			return err
+		}
+		options.Naming = naming
+	}
+	if cfg != nil && cfg.Path() != "" {
+		options.Files = append(options.Files, cfg.Path())
+	}
+	if options.Redactor == nil {
+		options.Redactor = masalog.Sensitive()
+	}
+	options.SystemInfo = true
+	return masalog.CollectBundle(w, options)
+}
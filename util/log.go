@@ -10,7 +10,9 @@
 package util
 
 import (
+	"expvar"
 	"flag"
+	"fmt"
 	"github.com/go-errors/errors"
 	"github.com/masagroup/sw.golibs/config"
 	masalog "github.com/masagroup/sw.golibs/log"
@@ -21,7 +23,9 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 )
 
 // CatchPanic recovers from any panic and logs the stack trace.
@@ -85,6 +89,33 @@ func redirectStderr(f *os.File) {
 	}
 }
 
+// closers closes all its elements in order and returns the first error.
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var err error
+	for _, closer := range c {
+		cerr := closer.Close()
+		if err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// debugLogPath returns the path of the start-up log written by Parse.
+func debugLogPath(logPrefix string) string {
+	return filepath.Join(filepath.Dir(os.Args[0]), "Debug", logPrefix+".log")
+}
+
+// writeBanner writes the application version and command line to w, one
+// entry at a time.
+func writeBanner(w io.Writer, logPrefix string) {
+	prefix := "<" + logPrefix + "> "
+	fmt.Fprintln(w, prefix+"Sword "+logPrefix+" "+SWORD_VERSION+" - copyright Masa Group 2016")
+	fmt.Fprintln(w, prefix+"command line", masalog.RedactArgs(os.Args))
+}
+
 // Parse configures the default logger and parses application arguments.
 // It returns a closer and the configuration file path.
 // Stdout will receive all logs.
@@ -93,21 +124,40 @@ func redirectStderr(f *os.File) {
 // If a log file is configured (using the -log flag) all logs will be sent to
 // that file except for stderr which goes to a generic debug file.
 func Parse(logPrefix string) (io.Closer, *config.Config) {
-	log.SetOutput(masalog.MakeCollapsingWriter(os.Stderr))
+	log.SetOutput(masalog.NewRedactingWriter(masalog.MakeCollapsingWriter(os.Stderr), nil))
 	log.SetPrefix("<" + logPrefix + "> ")
 	log.SetFlags(0)
-	debug, f, err := makeDebugLog(filepath.Join(filepath.Dir(os.Args[0]), "Debug", logPrefix+".log"))
+	debug, f, err := makeDebugLog(debugLogPath(logPrefix))
 	if err == nil {
 		defer f.Close()
-		log.SetOutput(masalog.MakeCollapsingWriter(io.MultiWriter(f, os.Stdout)))
+		log.SetOutput(masalog.NewRedactingWriter(masalog.MakeCollapsingWriter(io.MultiWriter(f, os.Stdout)), nil))
 		redirectStderr(f)
 	}
-	log.Println("command line", os.Args)
+	log.Println("command line", masalog.RedactArgs(os.Args))
 	file := flag.String("log", "", "optional log filename")
 	maxFiles := flag.Int("max-files", -1, "number of log files to keep when rotating, a negative value means infinite, defaults to -1")
 	maxSize := flag.Int64("max-size", 100, "log size in bytes to reach before rotating, defaults to 100, 0 disables rotation")
 	sizeUnit := flag.String("size-unit", "mbytes", "log size unit, valid values are 'bytes', 'kbytes', 'mbytes' or 'lines'")
+	maxAge := flag.Duration("max-age", 0, "maximum age of rotated log files to keep, like '336h', defaults to 0 which keeps them forever")
+	maxTotalSize := flag.Int64("max-total-size", 0, "maximum total size in mbytes of rotated log files to keep, defaults to 0 which means infinite")
+	rotateTime := flag.String("rotate-time", "", "log rotation schedule in addition to size, valid values are 'hourly', 'daily', 'midnight' or a duration like '30m', empty disables it")
+	logNaming := flag.String("log-naming", "", "rotated log files naming, valid values are 'time', 'numeric' or a time layout like '2006-01-02', defaults to 'time', previous values can follow so that their files are still pruned, like 'numeric,time'")
+	logQueue := flag.Int("log-queue", 0, "number of log entries buffered before reaching the log file, 0 writes synchronously")
+	logQueueDrop := flag.Bool("log-queue-drop", false, "drop log entries instead of blocking when the log queue is full")
+	logLock := flag.Bool("log-lock", false, "coordinate log rotations with other processes writing to the same log file")
+	compress := flag.Bool("compress", false, "compress rotated log files with gzip")
+	syslog := flag.String("syslog", "", "also send logs to syslog, 'local' for the local daemon or 'network:address' like 'udp:localhost:514'")
+	logBurst := flag.Int("log-burst", 0, "number of log entries written to the log file per second before sampling them, 0 disables sampling")
+	logSample := flag.Int("log-sample", 100, "once the log burst is reached, write one log entry in every log-sample, 0 drops them")
+	syslogFacility := flag.String("syslog-facility", "user", "syslog facility, like 'user', 'daemon' or 'local0'")
+	logFlush := flag.Duration("log-flush", 10*time.Second, "delay after which collapsed log entries counts get written, 0 waits for a different entry")
+	logFormat := flag.String("log-format", "text", "log file format, valid values are 'text' or 'json'")
+	logTime := flag.String("log-time", "default", "text log time stamps format, valid values are 'default', 'ms', 'rfc3339', 'rfc3339ms' or 'elapsed', the latter not being supported with a text log file")
+	logUTC := flag.Bool("log-utc", false, "stamp text logs with UTC instead of local time")
+	logLines := flag.String("log-lines", "first", "stamping of multi-line text log entries, valid values are 'first', 'each' or 'indent'")
+	logLevel := flag.String("log-level", "info", "minimum level of structured log entries, valid values are 'debug', 'info', 'warn' or 'error'")
 	debugPort := flag.Int("debug-port", 0, "start pprof http debug server on supplied port number")
+	logRing := flag.Int("log-ring", 1000, "number of log entries kept in memory and served on /debug/logs by the debug server, 0 disables it")
 	configFile := flag.String("config", "", "config filename")
 	flag.Parse()
 	config, err := config.NewConfig(*configFile,
@@ -120,49 +170,195 @@ func Parse(logPrefix string) (io.Closer, *config.Config) {
 	if err != nil {
 		log.Fatalf("unable to parse flags : %v", err)
 	}
+	level, err := masalog.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatalf("unable to parse flags : %v", err)
+	}
+	masalog.SetLevel(level)
+	timeFormat, err := masalog.ParseTimeFormat(*logTime)
+	if err != nil {
+		log.Fatalf("unable to parse flags : %v", err)
+	}
+	if *logTime == "elapsed" && len(*file) > 0 && *logFormat == "text" {
+		// Log file readers, like bundles or logstats, need absolute stamps.
+		log.Fatalf("unable to parse flags : log-time 'elapsed' cannot be used with a log file")
+	}
+	lineMode, err := masalog.ParseLineMode(*logLines)
+	if err != nil {
+		log.Fatalf("unable to parse flags : %v", err)
+	}
+	timeOptions := []masalog.TimeOption{timeFormat, masalog.WithLineMode(lineMode)}
+	if *logUTC {
+		timeOptions = append(timeOptions, masalog.WithUTC())
+	}
+	makeText := func(w io.Writer, options ...masalog.CollapseOption) *masalog.CollapsingWriter {
+		return masalog.NewCollapsingWriter(masalog.NewTimeWriter(w, timeOptions...), options...)
+	}
+	makeWriter := makeText
+	// makeBanner formats the banner of rotated files like their entries.
+	makeBanner := func(w io.Writer) io.Writer {
+		return masalog.NewTimeWriter(w, timeOptions...)
+	}
+	switch *logFormat {
+	case "text":
+	case "json":
+		makeWriter = masalog.MakeJSONCollapsingWriter
+		makeBanner = func(w io.Writer) io.Writer {
+			return masalog.NewJSONWriter(w)
+		}
+	default:
+		log.Fatalf("unable to parse flags : invalid log format: %q", *logFormat)
+	}
 	wd, err := os.Getwd()
 	if err == nil {
 		log.Println("working-directory", wd)
 	} else {
 		log.Println("working-directory", err)
 	}
-	var c io.Closer
+	outputs := []io.Writer{}
+	cs := closers{}
+	metrics := &masalog.Metrics{}
 	if len(*file) > 0 && *maxFiles != 0 {
 		dir := filepath.Dir(*file)
 		err := os.MkdirAll(dir, os.ModePerm)
 		if err != nil {
 			log.Fatalf("unable to create log file directory %v: %v", dir, err)
 		}
-		w, err := masalog.NewRotateWriter(*file, *maxFiles, *maxSize, *sizeUnit, true)
+		policy, err := masalog.ParseRotatePolicy(*rotateTime)
+		if err != nil {
+			log.Fatalf("unable to parse log rotation schedule: %v", err)
+		}
+		naming, err := masalog.ParseNamingScheme(*logNaming)
+		if err != nil {
+			log.Fatalf("unable to parse flags : %v", err)
+		}
+		// The first file receives the banner logged below, following ones
+		// get a copy so that they can be analysed on their own.
+		opened := false
+		options := []masalog.RotateOption{
+			masalog.WithMaxAge(*maxAge),
+			masalog.WithMaxTotalSize(*maxTotalSize * 1048576),
+			masalog.WithNaming(naming),
+			masalog.OnOpen(func(w io.Writer) {
+				if opened {
+					writeBanner(makeBanner(w), logPrefix)
+				}
+				opened = true
+			}),
+		}
+		if policy != nil {
+			options = append(options, masalog.WithRotatePolicy(policy))
+		}
+		if *compress {
+			options = append(options, masalog.WithCompression(masalog.GzipCompressor{}))
+		}
+		if *logLock {
+			options = append(options, masalog.WithFileLock())
+		}
+		w, err := masalog.NewRotateWriter(*file, *maxFiles, *maxSize, *sizeUnit, true, options...)
 		if err != nil {
 			log.Fatalf("unable to create log file %v: %v", *file, err)
 		}
-		log.SetOutput(masalog.MakeCollapsingWriter(io.MultiWriter(w, os.Stdout)))
-		c = w
-	} else {
-		log.SetOutput(masalog.MakeCollapsingWriter(os.Stdout))
+		metrics.Rotate = w
+		var out io.WriteCloser = w
+		if *logQueue > 0 {
+			metrics.Async = masalog.NewAsyncWriter(w, *logQueue, *logQueueDrop)
+			out = metrics.Async
+		}
+		cw := makeWriter(out, masalog.WithFlushInterval(*logFlush))
+		metrics.Collapse = cw
+		if *logBurst > 0 {
+			// Suppressed entries counts are written to the log file only.
+			sw := masalog.NewSamplingWriter(cw, *logBurst, *logSample,
+				masalog.WithSummaryPrefix("<"+logPrefix+"> "))
+			outputs = append(outputs, sw)
+			cs = append(cs, sw)
+			metrics.Sampling = sw
+		} else {
+			outputs = append(outputs, cw)
+		}
+		cs = append(cs, cw, out)
 	}
-	log.Println("Sword " + logPrefix + " " + SWORD_VERSION + " - copyright Masa Group 2016")
-	log.Println("command line", os.Args)
+	stdout := makeText(os.Stdout, masalog.WithFlushInterval(*logFlush))
+	outputs = append(outputs, stdout)
+	// Closing flushes pending repeat counts of every output.
+	cs = append(cs, stdout)
+	if *debugPort > 0 && expvar.Get("log") == nil {
+		// Log file counters are served on /debug/vars and /debug/metrics.
+		expvar.Publish("log", expvar.Func(func() interface{} {
+			return metrics.Values()
+		}))
+		http.HandleFunc("/debug/metrics", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			metrics.WritePrometheus(w, "log")
+		})
+	}
+	if *debugPort > 0 && *logRing > 0 {
+		ring := masalog.NewRingWriter(*logRing)
+		http.Handle("/debug/logs", ring)
+		rw := makeText(ring, masalog.WithFlushInterval(*logFlush))
+		outputs = append(outputs, rw)
+		cs = append(cs, rw)
+	}
+	if len(*syslog) > 0 {
+		facility, err := masalog.ParseFacility(*syslogFacility)
+		if err != nil {
+			log.Fatalf("unable to parse flags : %v", err)
+		}
+		cfg := masalog.SyslogConfig{AppName: logPrefix, Facility: facility}
+		if *syslog != "local" {
+			i := strings.Index(*syslog, ":")
+			if i < 0 {
+				log.Fatalf("unable to parse flags : invalid syslog address: %q", *syslog)
+			}
+			cfg.Network, cfg.Address = (*syslog)[:i], (*syslog)[i+1:]
+		}
+		w, err := masalog.NewSyslogWriter(cfg)
+		if err != nil {
+			log.Fatalf("unable to connect to syslog %v: %v", *syslog, err)
+		}
+		// Syslog entries are timestamped by the writer itself.
+		sw := masalog.NewCollapsingWriter(w, masalog.WithFlushInterval(*logFlush))
+		outputs = append(outputs, sw)
+		cs = append(cs, sw, w)
+	}
+	log.SetOutput(masalog.NewRedactingWriter(io.MultiWriter(outputs...), nil))
+	writeBanner(log.Writer(), logPrefix)
 	log.Println("debug", debug)
 	if len(*configFile) > 0 {
 		log.Println("config", *configFile)
 	}
+	if len(*syslog) > 0 {
+		log.Println("syslog", *syslog, *syslogFacility)
+	}
 	if len(*file) > 0 {
-		log.Println("log", *file)
-		if *maxFiles < 0 {
-			log.Println("max-files", *maxFiles, "(infinite)")
-		} else {
-			log.Println("max-files", *maxFiles)
-		}
-		log.Println("max-size", *maxSize)
-		log.Println("size-unit", *sizeUnit)
+		masalog.Info("log settings",
+			"log", *file,
+			"max-files", *maxFiles,
+			"max-size", *maxSize,
+			"size-unit", *sizeUnit,
+			"max-age", *maxAge,
+			"max-total-size", *maxTotalSize,
+			"rotate-time", *rotateTime,
+			"log-naming", *logNaming,
+			"compress", *compress,
+			"log-lock", *logLock,
+			"log-queue", *logQueue,
+			"log-queue-drop", *logQueueDrop,
+			"log-format", *logFormat,
+			"log-time", *logTime,
+			"log-utc", *logUTC,
+			"log-lines", *logLines,
+			"log-burst", *logBurst,
+			"log-sample", *logSample,
+			"log-flush", *logFlush,
+			"log-level", level)
 	}
 	if *debugPort > 0 {
-		log.Println("debug-port", *debugPort)
+		masalog.Info("starting debug server", "debug-port", *debugPort, "log-ring", *logRing)
 		Go(func() {
 			log.Println(http.ListenAndServe(":"+strconv.Itoa(*debugPort), nil))
 		})
 	}
-	return c, config
+	return cs, config
 }
@@ -13,6 +13,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	masalog "github.com/masagroup/sw.golibs/log"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
@@ -27,6 +28,9 @@ var (
 	Verbose     bool
 )
 
+// verbose prints requests and responses with sensitive values masked.
+var verbose = masalog.NewRedactingWriter(os.Stdout, nil)
+
 func init() {
 	Verbose = os.Getenv("MASA_DEBUG") != ""
 }
@@ -45,7 +49,7 @@ func do(verb, host, path, contentType, SID string, input []byte,
 
 	u := fmt.Sprintf("http://%s%s", host, path)
 	if Verbose {
-		fmt.Printf("---\n%s %s\n", verb, u)
+		fmt.Fprintf(verbose, "---\n%s %s\n", verb, u)
 	}
 	rq, err := http.NewRequest(verb, u, bytes.NewBuffer(input))
 	if err != nil {
@@ -60,14 +64,14 @@ func do(verb, host, path, contentType, SID string, input []byte,
 	if Verbose {
 		for k, values := range rq.Header {
 			for _, v := range values {
-				fmt.Printf("%s: %s\n", k, v)
+				fmt.Fprintf(verbose, "%s: %s\n", k, v)
 			}
 		}
 		if len(input) > 0 {
 			if isBinary(input) {
-				fmt.Printf("binary: %d bytes\n", len(input))
+				fmt.Fprintf(verbose, "binary: %d bytes\n", len(input))
 			} else {
-				fmt.Printf("%v\n", string(input))
+				fmt.Fprintf(verbose, "%v\n", string(input))
 			}
 		}
 	}
@@ -81,17 +85,17 @@ func do(verb, host, path, contentType, SID string, input []byte,
 	rsp, err := client.Do(rq)
 	if err != nil {
 		if Verbose {
-			fmt.Printf("error: %s\n\n", err)
+			fmt.Fprintf(verbose, "error: %s\n\n", err)
 		}
 		return err
 	}
 	defer rsp.Body.Close()
 	var body io.Reader = rsp.Body
 	if Verbose {
-		fmt.Println("->")
+		fmt.Fprintln(verbose, "->")
 		for k, values := range rsp.Header {
 			for _, v := range values {
-				fmt.Printf("%s: %s\n", k, v)
+				fmt.Fprintf(verbose, "%s: %s\n", k, v)
 			}
 		}
 		// This blocks until EOF, which might be different from the actual
@@ -100,12 +104,12 @@ func do(verb, host, path, contentType, SID string, input []byte,
 		if err != nil {
 			return err
 		}
-		fmt.Println(rsp.Status)
+		fmt.Fprintln(verbose, rsp.Status)
 		if len(data) > 0 {
 			if isBinary(data) {
-				fmt.Printf("binary: %d bytes\n", len(data))
+				fmt.Fprintf(verbose, "binary: %d bytes\n", len(data))
 			} else {
-				fmt.Printf("%v\n", string(data))
+				fmt.Fprintf(verbose, "%v\n", string(data))
 			}
 		}
 		body = bytes.NewBuffer(data)